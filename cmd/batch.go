@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"aicommits/internal/analyzer"
+	"aicommits/internal/config"
+	"aicommits/internal/diff"
+	"aicommits/internal/git"
+	"aicommits/internal/llm"
+	"aicommits/internal/ui"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var batchJobs int
+
+var batchCmd = &cobra.Command{
+	Use:   "batch <dir|glob>...",
+	Short: "为多个仓库的暂存区同时生成提交信息，确认后一起提交",
+	Long: `在指定的目录中查找有暂存变更的仓库，同时为它们生成提交信息，在同一张表中确认后一起提交，
+最后输出每个仓库成功或失败的汇总。参数可以是仓库目录、包含多个仓库的目录，或者 glob，例如:
+  aicommits batch ~/code/*-service
+  aicommits batch ~/code/platform -j 8
+
+批量模式只使用暂存区的 diff 和仓库的基本信息，不做 Go 语义摘要和历史提交风格学习；
+配置中的 trailers 和 signoff 通过 git commit --trailer / --signoff 添加。`,
+	Args:        checkArgs(cobra.MinimumNArgs(1)),
+	Annotations: map[string]string{annotationNoRepo: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if err := cfg.RequireAPIKey(); err != nil {
+			return err
+		}
+
+		repos, err := findRepos(args)
+		if err != nil {
+			return usageError{err}
+		}
+
+		var staged []git.Repo
+		for _, r := range repos {
+			if r.HasStagedChanges() {
+				staged = append(staged, r)
+			}
+		}
+		if len(staged) == 0 {
+			return fmt.Errorf("%w: 找到 %d 个仓库，但都没有暂存的变更", git.ErrEmptyDiff, len(repos))
+		}
+		if skipped := len(repos) - len(staged); skipped > 0 {
+			fmt.Printf("⏭️ 跳过 %d 个没有暂存变更的仓库\n", skipped)
+		}
+
+		client := newClient(cfg)
+		commitArgs := batchCommitArgs(cfg)
+		items := make([]ui.BatchItem, len(staged))
+		for i, r := range staged {
+			items[i] = ui.BatchItem{
+				Name:   r.Name(),
+				Branch: r.Branch(),
+				Generate: func(ctx context.Context) (string, error) {
+					rawDiff, err := r.StagedDiff()
+					if err != nil {
+						return "", fmt.Errorf("Git错误: %w", err)
+					}
+					opts := batchPromptOptions(cfg, r, rawDiff)
+					return llm.WithPostProcess(client, postProcessors(cfg, opts)...).GenerateCommitMessage(ctx, opts)
+				},
+				Commit: func(msg string) (string, error) {
+					return r.Commit(msg, commitArgs...)
+				},
+			}
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		finalModel, err := tea.NewProgram(ui.NewBatchModel(ctx, items, batchJobs)).Run()
+		if err != nil {
+			return fmt.Errorf("UI 错误: %w", err)
+		}
+		m, ok := finalModel.(ui.BatchModel)
+		if !ok {
+			return nil
+		}
+		if !m.CommitStarted() {
+			return ErrCancelled
+		}
+		// 提交中途被中断时同样输出已经处理的仓库
+		err = reportBatch(m.Results())
+		if !m.Confirmed {
+			return ErrCancelled
+		}
+		return err
+	},
+}
+
+// findRepos 查找参数中的仓库：参数可以是 glob，匹配到的目录本身不是仓库的根目录时查找它的直接子目录
+func findRepos(patterns []string) ([]git.Repo, error) {
+	var repos []git.Repo
+	seen := map[string]bool{}
+	// add 只接受以 dir 为根目录的仓库，dir 位于外层仓库 (例如 $HOME 中的 dotfiles 仓库) 中时不算
+	add := func(dir string) bool {
+		r, err := git.Discover(dir)
+		if err != nil || r.TopLevel != realPath(dir) {
+			return false
+		}
+		if !seen[r.TopLevel] {
+			seen[r.TopLevel] = true
+			repos = append(repos, r)
+		}
+		return true
+	}
+
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 glob %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%s 没有匹配任何目录", pattern)
+		}
+
+		for _, dir := range matches {
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				continue
+			}
+			if add(dir) {
+				continue
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				continue
+			}
+			for _, e := range entries {
+				if !e.IsDir() {
+					continue
+				}
+				add(filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("%s 中没有找到 git 仓库", strings.Join(patterns, " "))
+	}
+	slices.SortFunc(repos, func(a, b git.Repo) int { return strings.Compare(a.TopLevel, b.TopLevel) })
+	return repos, nil
+}
+
+// realPath 返回 dir 的绝对路径并解析符号链接，与 git rev-parse --show-toplevel 的输出一致
+func realPath(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.Clean(dir)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+// batchPromptOptions 组装批量模式的 PromptOptions，所有信息都从 r 中读取，不依赖当前目录
+func batchPromptOptions(cfg *config.Config, r git.Repo, rawDiff string) llm.PromptOptions {
+	files := diff.Parse(rawDiff)
+	deps := analyzer.SummarizeDeps(files)
+	for i, f := range files {
+		if analyzer.IsLockfile(f.Path()) {
+			files[i] = f.WithoutHunks()
+		}
+	}
+	stat, _ := r.StagedStat()
+
+	opts := llm.PromptOptions{
+		Language:              cfg.Language,
+		Diff:                  diff.Shrink(files, cfg.MaxDiffSize, nil),
+		Dependencies:          analyzer.FormatDeps(deps),
+		Stat:                  stat,
+		Branch:                r.Branch(),
+		RecentCommits:         r.RecentCommits(recentCommitCount),
+		Types:                 llm.DefaultTypes,
+		WithDescription:       cfg.WithDescription,
+		SubjectSeparateSymbol: cfg.SubjectSeparateSymbol,
+		TemplateDirs:          config.PromptDirs(r.TopLevel),
+	}
+	if cfg.TicketPlacement != "" {
+		opts.Tickets, _ = git.TicketIDs(opts.Branch, cfg.TicketPattern)
+	}
+	return opts
+}
+
+// batchCommitArgs 返回批量提交时的 git commit 参数，trailer 交给 git 添加，签名使用各仓库自己的身份
+func batchCommitArgs(cfg *config.Config) []string {
+	args := slices.Clone(cfg.CommitArgs)
+	if cfg.Signoff {
+		args = append(args, "--signoff")
+	}
+	for _, t := range cfg.Trailers {
+		args = append(args, "--trailer", t)
+	}
+	return args
+}
+
+// reportBatch 输出每个仓库的结果，有仓库失败时返回错误
+func reportBatch(results []ui.BatchResult) error {
+	var committed, failed, skipped, interrupted int
+	var lines []string
+	var commitErrs, generateErrs []error
+	for _, r := range results {
+		switch {
+		case r.Committed:
+			committed++
+			// git commit 输出的第一行形如 "[main abc1234] subject"
+			summary, _, _ := strings.Cut(strings.TrimSpace(r.Output), "\n")
+			lines = append(lines, fmt.Sprintf("✅ %s  %s", r.Name, summary))
+		case r.Err != nil:
+			failed++
+			lines = append(lines, fmt.Sprintf("❌ %s  %v", r.Name, r.Err))
+			if errors.Is(r.Err, git.ErrCommitRejected) {
+				commitErrs = append(commitErrs, r.Err)
+			} else {
+				generateErrs = append(generateErrs, r.Err)
+			}
+		case r.Unknown:
+			interrupted++
+			lines = append(lines, fmt.Sprintf("⚠️ %s  退出时仍在提交，请检查仓库状态", r.Name))
+		case r.Cancelled:
+			interrupted++
+			lines = append(lines, fmt.Sprintf("⏭️ %s  已中断，未提交", r.Name))
+		default:
+			skipped++
+			lines = append(lines, fmt.Sprintf("⏭️ %s  未选中", r.Name))
+		}
+	}
+
+	fmt.Printf("\n📊 批量提交结果: 成功 %d，失败 %d，跳过 %d", committed, failed, skipped)
+	if interrupted > 0 {
+		fmt.Printf("，中断 %d", interrupted)
+	}
+	fmt.Println()
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	if failed == 0 {
+		return nil
+	}
+	// 有提交失败时按提交失败处理 (退出码 8)，只有生成失败时保留模型错误的类别
+	errs := commitErrs
+	if len(errs) == 0 {
+		errs = generateErrs
+	}
+	return batchError{failed: failed, errs: errs}
+}
+
+// batchError 是批量模式中有仓库失败时返回的错误，每个仓库的错误已经在结果中输出过
+type batchError struct {
+	failed int
+	errs   []error
+}
+
+func (e batchError) Error() string {
+	return fmt.Sprintf("%d 个仓库失败", e.failed)
+}
+
+func (e batchError) Unwrap() []error {
+	return e.errs
+}
+
+func init() {
+	batchCmd.Flags().IntVarP(&batchJobs, "jobs", "j", 4, "Maximum number of repositories processed at the same time")
+	rootCmd.AddCommand(batchCmd)
+}
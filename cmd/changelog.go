@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"aicommits/internal/changelog"
+	"aicommits/internal/config"
+	"aicommits/internal/git"
+	"aicommits/internal/llm"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	changelogFile    string
+	changelogWrite   bool
+	changelogPolish  bool
+	changelogAll     bool
+	changelogVersion string
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog [<from>..<to>]",
+	Short: "根据 Conventional Commits 生成变更日志",
+	Long: `将范围内的提交按类型和 scope 分组，生成 Keep a Changelog 格式的版本小节。
+不指定范围时，从最近的 tag (git describe --tags) 到 HEAD；没有 tag 时包含全部历史。
+HEAD 本身有 tag 时，范围是上一个 tag 到这个 tag。
+<to> 是 tag 时以它作为版本号，否则写入 [Unreleased] 小节。`,
+	Args: checkArgs(cobra.MaximumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		var from, to string
+		if len(args) == 1 {
+			from, to, _ = strings.Cut(args[0], "..")
+		} else if tag := git.TagAt("HEAD"); tag != "" {
+			// HEAD 刚打上发布 tag 时生成这个版本的小节，范围从上一个 tag 开始
+			from, to = git.LatestTag("HEAD^"), tag
+		} else {
+			from = git.LatestTag("HEAD")
+		}
+		if to == "" {
+			to = "HEAD"
+		}
+		revRange := to
+		if from != "" {
+			revRange = from + ".." + to
+		}
+
+		entries, err := git.RangeCommits(revRange)
+		if err != nil {
+			return fmt.Errorf("无法读取提交范围 %s: %w", revRange, err)
+		}
+
+		version, date := changelogVersion, time.Now().Format("2006-01-02")
+		if version == "" {
+			if git.IsTag(to) {
+				version, date = to, git.CommitDate(to)
+			} else {
+				version, date = "Unreleased", ""
+			}
+		}
+
+		commits := make([]changelog.Commit, 0, len(entries))
+		for _, e := range entries {
+			commits = append(commits, changelog.Commit{Hash: e.Hash, Message: e.Message})
+		}
+		release := changelog.Build(version, date, commits, changelogAll)
+		if release.IsEmpty() {
+			fmt.Fprintf(os.Stderr, "⚠️ %s 中没有可以写入变更日志的提交\n", revRange)
+			return nil
+		}
+		notes := release.Render()
+
+		if changelogPolish {
+			if err := cfg.RequireAPIKey(); err != nil {
+				return err
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			fmt.Fprintln(os.Stderr, "⏳ 正在润色发布说明...")
+			notes, err = llm.PolishChangelog(ctx, newClient(cfg), llm.ChangelogOptions{
+				Language:     cfg.Language,
+				Notes:        notes,
+				TemplateDirs: config.PromptDirs(repo.TopLevel),
+			})
+			if err != nil {
+				return fmt.Errorf("润色失败: %w", err)
+			}
+		}
+
+		if !changelogWrite {
+			fmt.Println(notes)
+			return nil
+		}
+
+		existing, err := os.ReadFile(changelogFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("读取 %s 失败: %w", changelogFile, err)
+		}
+		updated := changelog.Prepend(string(existing), notes, version)
+		if err := os.WriteFile(changelogFile, []byte(updated), 0o644); err != nil {
+			return fmt.Errorf("写入 %s 失败: %w", changelogFile, err)
+		}
+		fmt.Fprintf(os.Stderr, "✅ 已将 %s 写入 %s\n", release.Heading(), changelogFile)
+		return nil
+	},
+}
+
+func init() {
+	changelogCmd.Flags().BoolVarP(&changelogWrite, "write", "w", false, "Write the section into the changelog file instead of printing it")
+	changelogCmd.Flags().StringVar(&changelogFile, "file", "CHANGELOG.md", "Changelog file to update with --write")
+	changelogCmd.Flags().BoolVar(&changelogPolish, "polish", false, "Let the LLM polish the grouped entries into release notes")
+	changelogCmd.Flags().BoolVar(&changelogAll, "all", false, "Include commits of other types and non-conventional commits under Other")
+	changelogCmd.Flags().StringVar(&changelogVersion, "version", "", "Version heading to use instead of the tag or Unreleased")
+	rootCmd.AddCommand(changelogCmd)
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"aicommits/internal/config"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/huh"
@@ -10,10 +11,12 @@ import (
 )
 
 var configCmd = &cobra.Command{
-	Use:   "config",
-	Short: "Config",
-	Run: func(cmd *cobra.Command, args []string) {
-		interactiveConfig()
+	Use:         "config",
+	Annotations: map[string]string{annotationNoRepo: "true"},
+	Short:       "Config",
+	Args:        checkArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return interactiveConfig()
 	},
 }
 
@@ -25,7 +28,7 @@ var providerModels = map[string][]string{
 }
 
 // interactiveConfig 启动交互式表单
-func interactiveConfig() {
+func interactiveConfig() error {
 	// 1. 读取现有配置作为默认值
 	currentCfg, _ := config.Load()
 	if currentCfg == nil {
@@ -72,8 +75,7 @@ func interactiveConfig() {
 	).Run()
 
 	if err != nil {
-		fmt.Println("❌ 配置已取消")
-		return
+		return ErrCancelled
 	}
 
 	switch provider {
@@ -113,7 +115,7 @@ func interactiveConfig() {
 			),
 		).Run()
 		if err != nil {
-			return
+			return ErrCancelled
 		}
 
 		if selectedModel != "manual" {
@@ -157,8 +159,7 @@ func interactiveConfig() {
 	).Run()
 
 	if err != nil {
-		fmt.Println("❌ 配置已取消")
-		return
+		return ErrCancelled
 	}
 
 	// 4. 保存配置
@@ -173,46 +174,63 @@ func interactiveConfig() {
 	}
 
 	if err := config.Save(newConfig); err != nil {
-		fmt.Printf("❌ 保存失败: %v\n", err)
-		return
+		return fmt.Errorf("保存失败: %w", err)
 	}
 
 	fmt.Println("✅ 配置已成功保存到 ~/.aicommits.yaml")
+	return nil
+}
+
+// settableKeys 是允许通过 set 命令修改的配置项
+var settableKeys = []string{
+	"api_key",
+	"model",
+	"base_url",
+	"subject_separate_symbol",
+	"examples",
+	"examples_mode",
+	"auto_style",
+	"max_diff_size",
+	"deps_in_body",
+	"ticket_pattern",
+	"ticket_placement",
+	"signoff",
+	"timeout",
 }
 
 var setCmd = &cobra.Command{
-	Use:   "set <key> <value>",
-	Short: "设置配置项",
-	Args:  cobra.ExactArgs(2), // 必须传入 key 和 value
-	Run: func(cmd *cobra.Command, args []string) {
+	Use:         "set <key> <value>",
+	Annotations: map[string]string{annotationNoRepo: "true"},
+	Short:       "设置配置项",
+	Args:        checkArgs(cobra.ExactArgs(2)), // 必须传入 key 和 value
+	RunE: func(cmd *cobra.Command, args []string) error {
 		key := strings.ToLower(args[0])
 		val := args[1]
 
 		// 简单的校验
-		validKeys := map[string]bool{
-			"api_key":                 true,
-			"model":                   true,
-			"base_url":                true,
-			"subject_separate_symbol": true,
+		if !slices.Contains(settableKeys, key) {
+			return usageError{fmt.Errorf("无效的配置项: %s\n仅支持: %s", key, strings.Join(settableKeys, ", "))}
 		}
 
-		if !validKeys[key] {
-			fmt.Printf("❌ 无效的配置项: %s\n仅支持: api_key, model, base_url, subject_separate_symbol\n", key)
-			return
+		if key == "ticket_pattern" {
+			if err := config.ValidateTicketPattern(val); err != nil {
+				return usageError{err}
+			}
 		}
 
 		if err := config.Set(key, val); err != nil {
-			fmt.Printf("❌ 保存配置失败: %v\n", err)
-			return
+			return fmt.Errorf("保存配置失败: %w", err)
 		}
 
 		fmt.Printf("✅ 已更新 %s\n", key)
+		return nil
 	},
 }
 
 var listCmd = &cobra.Command{
-	Use:   "list",
-	Short: "查看当前配置",
+	Use:         "list",
+	Annotations: map[string]string{annotationNoRepo: "true"},
+	Short:       "查看当前配置",
 	Run: func(cmd *cobra.Command, args []string) {
 		// 触发加载
 		config.Load()
@@ -224,5 +242,11 @@ func init() {
 	rootCmd.AddCommand(setCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(configCmd)
+	rootCmd.PersistentFlags().StringVarP(&chdir, "chdir", "C", "", "Run as if aicommits was started in <dir> (like git -C)")
 	rootCmd.Flags().BoolVarP(&shouldStageAll, "add", "a", false, "Stage all files before commit")
+	rootCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Choose files and hunks to stage before generating")
+	rootCmd.Flags().BoolVar(&worktree, "worktree", false, "Describe and commit all tracked changes in the working tree (git commit -a)")
+	rootCmd.Flags().StringVar(&fromRev, "from", "", "Print a message for the working tree against a revision (git diff <rev>) without committing")
+	rootCmd.Flags().StringVar(&patchFile, "patch", "", "Print a message for a patch file, or - for stdin, without committing")
+	rootCmd.Flags().BoolVar(&amend, "amend", false, "Regenerate the message of HEAD and amend it with staged changes")
 }
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"aicommits/internal/config"
+	"aicommits/internal/git"
+	"aicommits/internal/llm"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// 退出码，脚本和钩子可以据此判断失败的原因
+const (
+	ExitOK             = 0
+	ExitError          = 1   // 其他错误
+	ExitUsage          = 2   // 命令行参数错误
+	ExitConfig         = 3   // 配置无效或缺少 API Key
+	ExitEmptyDiff      = 4   // 没有可以提交的变更
+	ExitAuth           = 5   // API Key 无效或没有权限
+	ExitRateLimit      = 6   // 请求被限流
+	ExitNetwork        = 7   // 网络错误或请求超时
+	ExitCommitRejected = 8   // git commit 失败，包括被钩子拒绝
+	ExitCancelled      = 130 // 用户取消
+)
+
+// ErrCancelled 表示用户在界面中取消了操作
+var ErrCancelled = errors.New("已取消")
+
+// usageError 标记命令行参数错误
+type usageError struct{ error }
+
+func (e usageError) Unwrap() error { return e.error }
+
+// checkArgs 把 cobra 的参数校验错误标记为 usageError
+func checkArgs(fn cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := fn(cmd, args); err != nil {
+			return usageError{err}
+		}
+		return nil
+	}
+}
+
+// ExitCode 返回错误对应的退出码
+func ExitCode(err error) int {
+	var usage usageError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &usage):
+		return ExitUsage
+	case errors.Is(err, ErrCancelled), errors.Is(err, context.Canceled):
+		return ExitCancelled
+	case errors.Is(err, config.ErrInvalidConfig), errors.Is(err, config.ErrMissingAPIKey):
+		return ExitConfig
+	case errors.Is(err, git.ErrEmptyDiff):
+		return ExitEmptyDiff
+	case errors.Is(err, llm.ErrAuth):
+		return ExitAuth
+	case errors.Is(err, llm.ErrRateLimit):
+		return ExitRateLimit
+	case errors.Is(err, llm.ErrNetwork), errors.Is(err, context.DeadlineExceeded):
+		return ExitNetwork
+	case errors.Is(err, git.ErrCommitRejected):
+		return ExitCommitRejected
+	}
+	return ExitError
+}
+
+// reportError 把错误输出到标准错误
+func reportError(err error) {
+	if errors.Is(err, ErrCancelled) {
+		fmt.Fprintf(os.Stderr, "\n🚫 %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"aicommits/internal/changelog"
+	"aicommits/internal/git"
+	"aicommits/internal/semver"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	nextVersionPre string
+	nextVersionTag bool
+)
+
+var nextVersionCmd = &cobra.Command{
+	Use:   "next-version",
+	Short: "根据上个版本以来的提交类型推荐下一个版本号",
+	Long: `分析最近的语义化版本 tag 到 HEAD 之间的提交：
+破坏性变更 (! 或 BREAKING CHANGE) 提升 major，feat 提升 minor，其余提升 patch。
+推荐的版本号输出到标准输出，决定版本的提交输出到标准错误。`,
+	Args: checkArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tags, err := git.MergedTags("HEAD")
+		if err != nil {
+			return fmt.Errorf("无法读取 tag: %w", err)
+		}
+
+		// 以最近的正式版本为基准，预发布 tag 只用于计算预发布序号
+		var versions []semver.Version
+		base, baseTag := semver.Version{Prefix: "v"}, ""
+		for _, tag := range tags {
+			v, ok := semver.Parse(tag)
+			if !ok {
+				continue
+			}
+			versions = append(versions, v)
+			if !v.IsPrerelease() && (baseTag == "" || v.Compare(base) > 0) {
+				base, baseTag = v, tag
+			}
+		}
+
+		revRange := "HEAD"
+		if baseTag != "" {
+			revRange = baseTag + "..HEAD"
+		}
+		entries, err := git.RangeCommits(revRange)
+		if err != nil {
+			return fmt.Errorf("无法读取提交范围 %s: %w", revRange, err)
+		}
+		if len(entries) == 0 {
+			return fmt.Errorf("%w: %s 之后没有新的提交", git.ErrEmptyDiff, baseTag)
+		}
+
+		level := semver.None
+		for _, e := range entries {
+			level = max(level, semver.Impact(e.Message))
+		}
+		next := base.Bump(level)
+		if nextVersionPre != "" {
+			next = next.Prerelease(nextVersionPre, versions)
+		}
+
+		if baseTag == "" {
+			fmt.Fprintln(os.Stderr, "📌 当前版本: (无语义化版本 tag)")
+		} else {
+			fmt.Fprintf(os.Stderr, "📌 当前版本: %s\n", baseTag)
+		}
+		fmt.Fprintf(os.Stderr, "💡 建议版本: %s (%s)\n\n", next, level)
+		fmt.Fprintf(os.Stderr, "决定版本的提交 (%d/%d):\n", countImpact(entries, level), len(entries))
+		for _, e := range entries {
+			if semver.Impact(e.Message) == level {
+				fmt.Fprintf(os.Stderr, "  %s %s\n", e.Hash[:7], e.Subject())
+			}
+		}
+		fmt.Println(next)
+
+		if !nextVersionTag {
+			return nil
+		}
+		if git.IsTag(next.String()) {
+			return fmt.Errorf("tag %s 已存在", next)
+		}
+		if err := git.CreateTag(next.String(), tagMessage(next.String(), entries)); err != nil {
+			return fmt.Errorf("创建 tag 失败: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "✅ 已创建附注 tag %s\n", next)
+		return nil
+	},
+}
+
+func countImpact(entries []git.LogEntry, level semver.Level) int {
+	count := 0
+	for _, e := range entries {
+		if semver.Impact(e.Message) == level {
+			count++
+		}
+	}
+	return count
+}
+
+// tagMessage 以版本号为标题，按变更日志分类列出范围内的提交作为 tag 说明
+func tagMessage(version string, entries []git.LogEntry) string {
+	commits := make([]changelog.Commit, 0, len(entries))
+	for _, e := range entries {
+		commits = append(commits, changelog.Commit{Hash: e.Hash, Message: e.Message})
+	}
+	notes := changelog.Build(version, time.Now().Format("2006-01-02"), commits, true).Render()
+
+	// 去掉 "## [version] - date" 标题行，以版本号作为 tag 说明的标题
+	_, sections, _ := strings.Cut(notes, "\n")
+	return version + "\n\n" + strings.TrimSpace(sections) + "\n"
+}
+
+func init() {
+	nextVersionCmd.Flags().StringVar(&nextVersionPre, "pre", "", "Recommend a pre-release version with this identifier (e.g. rc, beta)")
+	nextVersionCmd.Flags().BoolVar(&nextVersionTag, "tag", false, "Create an annotated tag for the recommended version")
+	rootCmd.AddCommand(nextVersionCmd)
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"aicommits/internal/config"
+	"aicommits/internal/git"
+	"aicommits/internal/llm"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	prBase   string
+	prOutput string
+)
+
+var prCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "生成 Pull Request 的标题和描述",
+	Long: `根据当前分支相对于目标分支的 diff 和提交记录，生成 PR 标题和 Markdown 描述。
+目标分支默认为当前分支的上游分支，上游就是同名远程分支时使用 origin 的默认分支。
+
+默认将标题和描述一起输出到标准输出；使用 --output 时描述写入文件，标准输出只保留标题，例如:
+  gh pr create --title "$(aicommits pr -o body.md)" --body-file body.md`,
+	Args: checkArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// 标准输出留给生成结果，提示信息都输出到标准错误
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if err := cfg.RequireAPIKey(); err != nil {
+			return err
+		}
+
+		base := prBase
+		if base == "" {
+			base = defaultPRBase()
+		}
+		if base == "" {
+			return usageError{fmt.Errorf("无法确定目标分支，请使用 --base 指定")}
+		}
+
+		mergeBase, err := git.MergeBase(base, "HEAD")
+		if err != nil {
+			return fmt.Errorf("Git错误: %w", err)
+		}
+		commits, err := git.RangeCommits(mergeBase + "..HEAD")
+		if err != nil {
+			return fmt.Errorf("Git错误: %w", err)
+		}
+		if len(commits) == 0 {
+			return fmt.Errorf("%w: 当前分支相对于 %s 没有新的提交", git.ErrEmptyDiff, base)
+		}
+
+		spec := git.DiffSpec{From: mergeBase, To: "HEAD"}
+		prDiff, err := spec.Diff()
+		if err != nil {
+			return fmt.Errorf("Git错误: %w", err)
+		}
+
+		opts := llm.PROptions{
+			PromptOptions: buildPromptOptions(cfg, prDiff, spec),
+			Base:          base,
+		}
+		for _, c := range commits {
+			opts.Commits = append(opts.Commits, c.Message)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		fmt.Fprintf(os.Stderr, "⏳ 正在根据 %d 个提交生成 PR 描述 (目标分支 %s)...\n", len(commits), base)
+		title, body, err := llm.GeneratePR(ctx, newClient(cfg), opts)
+		if err != nil {
+			return fmt.Errorf("生成失败: %w", err)
+		}
+
+		if prOutput == "" {
+			fmt.Printf("%s\n\n%s\n", title, body)
+			return nil
+		}
+		if err := os.WriteFile(prOutput, []byte(body+"\n"), 0o644); err != nil {
+			return fmt.Errorf("写入 %s 失败: %w", prOutput, err)
+		}
+		fmt.Println(title)
+		fmt.Fprintf(os.Stderr, "✅ PR 描述已写入 %s\n", prOutput)
+		return nil
+	},
+}
+
+// defaultPRBase 返回当前分支的上游分支；上游就是同名的远程分支时，使用 origin 的默认分支
+func defaultPRBase() string {
+	upstream := git.Upstream()
+	if upstream != "" {
+		_, name, _ := strings.Cut(upstream, "/")
+		if name != repo.Branch() {
+			return upstream
+		}
+	}
+	if def := git.RemoteDefaultBranch(); def != "" {
+		return def
+	}
+	return upstream
+}
+
+func init() {
+	prCmd.Flags().StringVar(&prBase, "base", "", "Target branch of the pull request")
+	prCmd.Flags().StringVarP(&prOutput, "output", "o", "", "Write the description to a file and print only the title")
+	rootCmd.AddCommand(prCmd)
+}
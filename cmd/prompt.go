@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"aicommits/internal/analyzer"
+	"aicommits/internal/config"
+	"aicommits/internal/diff"
+	"aicommits/internal/git"
+	"aicommits/internal/llm"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// recentCommitCount 是传给模板的最近提交数量
+	recentCommitCount = 10
+	// historySampleSize 是识别提交风格和挑选示例时读取的历史提交数量
+	historySampleSize = 100
+	// submoduleLogLimit 是每个子模块最多列出的提交数量
+	submoduleLogLimit = 20
+)
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "提示词模板相关命令",
+}
+
+var promptShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "打印基于当前暂存区渲染后的完整提示词",
+	Args:  checkArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		stagedDiff, err := git.GetStagedDiff()
+		if err != nil {
+			return fmt.Errorf("Git错误: %w", err)
+		}
+		if stagedDiff == "" {
+			fmt.Println("⚠️ 暂存区为空，提示词中的 diff 将为空")
+		}
+
+		messages, err := llm.ConstructMessages(buildPromptOptions(cfg, stagedDiff, git.DiffSpec{}))
+		if err != nil {
+			return fmt.Errorf("提示词渲染失败: %w", err)
+		}
+
+		for _, msg := range messages {
+			fmt.Printf("===== %s =====\n%s\n\n", msg.Role, msg.Content)
+		}
+		return nil
+	},
+}
+
+// diffSource 是变更的来源：仓库中两个版本之间的 diff (git.DiffSpec)，或补丁文件 (git.Patch)
+type diffSource interface {
+	analyzer.Source
+	Diff() (string, error)
+	Stat() (string, error)
+	Files() ([]string, error)
+}
+
+// buildPromptOptions 收集模板所需的仓库信息并组装 PromptOptions
+// rawDiff 是 spec 描述的变更的 diff 文本，历史提交从变更之前 (spec.From) 开始读取
+func buildPromptOptions(cfg *config.Config, rawDiff string, spec diffSource) llm.PromptOptions {
+	var historyBase string
+	if s, ok := spec.(git.DiffSpec); ok {
+		historyBase = s.From
+	}
+	return buildPromptOptionsAt(cfg, rawDiff, spec, historyBase)
+}
+
+// buildPromptOptionsAt 与 buildPromptOptions 相同，历史提交从 historyBase 开始读取，
+// 为一段提交中的每一个分别生成时传入整段之前的版本，避免把同一段中的其他提交当作示例
+func buildPromptOptionsAt(cfg *config.Config, rawDiff string, spec diffSource, historyBase string) llm.PromptOptions {
+	stat, _ := spec.Stat()
+
+	// Go 代码的语义摘要能表达变更意图，diff 超出预算时优先省略已被摘要覆盖的文件
+	files := diff.Parse(rawDiff)
+	summary := analyzer.SummarizeGo(files, spec)
+
+	// 依赖变化以列表的形式提供，锁文件的 diff 只保留头部
+	deps := analyzer.SummarizeDeps(files)
+	for i, f := range files {
+		if analyzer.IsLockfile(f.Path()) {
+			files[i] = f.WithoutHunks()
+		}
+	}
+
+	// 子模块指针的变化在 diff 中只有两个 hash，改为列出其间的提交
+	submodules := analyzer.SummarizeSubmodules(files, func(path, from, to string) ([]string, error) {
+		return repo.SubmoduleLog(path, from, to, submoduleLogLimit)
+	})
+
+	opts := llm.PromptOptions{
+		Language:              cfg.Language,
+		Diff:                  diff.Shrink(files, cfg.MaxDiffSize, func(f diff.File) bool { return summary.Covers(f.Path()) }),
+		Summary:               summary.String(),
+		Dependencies:          analyzer.FormatDeps(deps),
+		Submodules:            analyzer.FormatSubmodules(submodules),
+		Stat:                  stat,
+		Branch:                repo.Branch(),
+		RecentCommits:         repo.RecentCommits(recentCommitCount),
+		Types:                 llm.DefaultTypes,
+		WithDescription:       cfg.WithDescription,
+		SubjectSeparateSymbol: cfg.SubjectSeparateSymbol,
+		TemplateDirs:          config.PromptDirs(repo.TopLevel),
+	}
+
+	if files, err := spec.Files(); err == nil {
+		opts.Scope = git.InferScope(files, cfg.Scopes)
+	}
+
+	// ticket_pattern 在加载配置时已经校验过
+	if cfg.TicketPlacement != "" {
+		opts.Tickets, _ = git.TicketIDs(opts.Branch, cfg.TicketPattern)
+	}
+
+	if cfg.AutoStyle || cfg.Examples > 0 {
+		// 历史只读取变更之前的提交，重写和压缩时不会把被描述的提交本身当作示例
+		if commits, err := git.LogCommits(historyBase, historySampleSize); err == nil {
+			changed, _ := spec.Files()
+			applyHistory(&opts, cfg, commits, changed)
+		}
+	}
+	return opts
+}
+
+// applyHistory 根据历史提交识别风格约定和语言，并挑选示例提交，files 是本次变更的文件
+func applyHistory(opts *llm.PromptOptions, cfg *config.Config, commits []git.LogEntry, files []string) {
+	if cfg.AutoStyle {
+		subjects := make([]string, 0, len(commits))
+		for _, c := range commits {
+			subjects = append(subjects, c.Subject())
+		}
+		opts.Convention = llm.DetectConvention(subjects)
+		if lang := llm.DetectLanguage(subjects); lang != "" {
+			opts.Language = lang
+		}
+	}
+
+	if cfg.Examples > 0 {
+		examples := commits
+		if cfg.ExamplesMode == config.ExamplesModeSimilar {
+			examples = git.SimilarCommits(commits, files, cfg.Examples)
+		} else if len(examples) > cfg.Examples {
+			examples = examples[:cfg.Examples]
+		}
+		for _, c := range examples {
+			opts.Examples = append(opts.Examples, c.Message)
+		}
+	}
+}
+
+func init() {
+	promptCmd.AddCommand(promptShowCmd)
+	rootCmd.AddCommand(promptCmd)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"aicommits/internal/config"
+	"aicommits/internal/git"
+	"aicommits/internal/ui"
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var rewordCmd = &cobra.Command{
+	Use:   "reword <range>",
+	Short: "为一段历史提交重新生成提交信息",
+	Long: `为 <range> 中的每个提交根据它自己的 diff 重新生成提交信息，
+在对照表中逐条采用或编辑后，通过非交互式 rebase 写回历史，作者信息和作者时间保持不变。
+
+<range> 可以是 main..HEAD 这样的 revision range，只写一个版本时表示从该版本到 HEAD。`,
+	Args: checkArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if err := cfg.RequireAPIKey(); err != nil {
+			return err
+		}
+
+		revRange := args[0]
+		if !strings.Contains(revRange, "..") {
+			revRange += "..HEAD"
+		}
+
+		commits, err := git.RangeCommits(revRange)
+		if err != nil {
+			return fmt.Errorf("无法读取提交范围 %s: %w", revRange, err)
+		}
+		if len(commits) == 0 {
+			fmt.Printf("⚠️ %s 中没有提交\n", revRange)
+			return nil
+		}
+		if git.HasMerges(revRange) {
+			return fmt.Errorf("范围中包含合并提交，暂不支持重写")
+		}
+		if !git.IsAncestor(commits[len(commits)-1].Hash) {
+			return fmt.Errorf("只能重写当前分支 HEAD 之前的提交")
+		}
+
+		// 每个提交都根据它自己引入的 diff 生成新信息，历史示例只取整段之前的提交
+		items := make([]ui.RewordItem, 0, len(commits))
+		var historyBase string
+		for i, c := range commits {
+			spec, err := git.CommitSpec(c.Hash)
+			if err != nil {
+				return fmt.Errorf("Git错误: %w", err)
+			}
+			if i == 0 {
+				historyBase = spec.From
+			}
+			commitDiff, err := spec.Diff()
+			if err != nil {
+				return fmt.Errorf("Git错误: %w", err)
+			}
+
+			opts := buildPromptOptionsAt(cfg, commitDiff, spec, historyBase)
+			opts.PreviousMessage = c.Message
+			items = append(items, ui.RewordItem{Hash: c.Hash, Old: c.Message, Options: opts})
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		finalModel, err := tea.NewProgram(ui.NewRewordModel(ctx, perOptionsClient{Client: newClient(cfg), cfg: cfg}, items)).Run()
+		if err != nil {
+			return fmt.Errorf("UI 错误: %w", err)
+		}
+
+		m, ok := finalModel.(ui.RewordModel)
+		if !ok || !m.Confirmed {
+			return ErrCancelled
+		}
+
+		messages := map[string]string{}
+		for _, item := range m.Items {
+			msg := strings.TrimSpace(item.New)
+			if item.Accepted && msg != "" && msg != strings.TrimSpace(item.Old) {
+				messages[item.Hash] = msg
+			}
+		}
+		if len(messages) == 0 {
+			fmt.Println("⚠️ 没有需要重写的提交")
+			return nil
+		}
+
+		if err := git.RewordCommits(messages); err != nil {
+			return fmt.Errorf("重写失败: %w", err)
+		}
+		fmt.Printf("✅ 已重写 %d 个提交的信息\n", len(messages))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rewordCmd)
+}
@@ -2,11 +2,17 @@ package cmd
 
 import (
 	"aicommits/internal/config"
+	"aicommits/internal/diff"
 	"aicommits/internal/git"
 	"aicommits/internal/llm"
 	"aicommits/internal/ui" // 引入 UI 包
 	"context"
 	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -14,84 +20,422 @@ import (
 )
 
 var shouldStageAll bool
+var amend bool
+var interactive bool
+var worktree bool
+var fromRev string
+var patchFile string
+var chdir string
+
+// repo 是命令所在的仓库，在需要仓库的命令执行之前由 PersistentPreRunE 设置
+var repo git.Repo
+
+// annotationNoRepo 标记不需要在 git 仓库中运行的命令
+const annotationNoRepo = "no-repo"
+
 var rootCmd = &cobra.Command{
-	Use:   "aicommits",
+	Use:   "aicommits [flags] [<pathspec>...] [-- <git commit args>]",
 	Short: "使用AI编写Git提交日志",
-	Run: func(cmd *cobra.Command, args []string) {
+	Example: `  aicommits -- -S --no-verify
+  aicommits -- --author="Name <mail>" --date=now
+  aicommits --worktree
+  aicommits src/ docs/README.md
+  aicommits --from main
+  git format-patch -1 --stdout | aicommits --patch -`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// 与 git -C 相同，之后的所有 git 命令都在 dir 中执行
+		if chdir != "" {
+			if err := os.Chdir(chdir); err != nil {
+				return usageError{fmt.Errorf("无法切换到目录 %s: %w", chdir, err)}
+			}
+		}
+		if !needsRepo(cmd) {
+			return nil
+		}
+		var err error
+		repo, err = git.Discover("")
+		return err
+	},
+	Args: func(cmd *cobra.Command, args []string) error {
+		paths, passthrough := splitArgs(cmd, args)
+		draft := fromRev != "" || patchFile != ""
+		switch {
+		case fromRev != "" && patchFile != "":
+			return usageError{fmt.Errorf("--from 和 --patch 不能同时使用")}
+		case draft && (shouldStageAll || amend || interactive || worktree || len(passthrough) > 0):
+			return usageError{fmt.Errorf("--from 和 --patch 只生成提交信息，不能与 --add、--amend、--interactive、--worktree 或 git commit 参数一起使用")}
+		case patchFile != "" && len(paths) > 0:
+			return usageError{fmt.Errorf("--patch 不能与 pathspec 一起使用")}
+		case interactive && (worktree || len(paths) > 0):
+			return usageError{fmt.Errorf("--interactive 基于暂存区生成，不能与 --worktree 或 pathspec 一起使用")}
+		case amend && len(paths) > 0:
+			return usageError{fmt.Errorf("--amend 不能与 pathspec 一起使用")}
+		}
+		if err := git.CheckPathspecs(paths); err != nil {
+			return usageError{err}
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// 1. 加载配置
 		cfg, err := config.Load()
 		if err != nil {
-			fmt.Printf("❌ 配置加载失败: %v\n", err)
-			return
+			return err
 		}
 
 		// 检查必要参数
-		if cfg.APIKey == "" {
-			fmt.Println("❌ 未检测到 API Key。")
-			fmt.Println("请先运行配置命令:")
-			fmt.Println("  aicommits config")
-			return
+		if err := cfg.RequireAPIKey(); err != nil {
+			return err
+		}
+
+		paths, passthrough := splitArgs(cmd, args)
+
+		// 补丁文件和 git diff <rev> 无法直接提交，只生成提交信息
+		if patchFile != "" {
+			patch, err := readPatch(patchFile)
+			if err != nil {
+				return err
+			}
+			return draftMessage(cfg, patch)
+		}
+		if fromRev != "" {
+			return draftMessage(cfg, git.DiffSpec{From: fromRev, Worktree: true, Paths: paths})
 		}
 
 		if shouldStageAll {
 			if err := git.StageAll(); err != nil {
-				fmt.Printf("❌ 无法将变更加入暂存区: %v\n", err)
-				return
+				return fmt.Errorf("无法将变更加入暂存区: %w", err)
+			}
+		}
+
+		// 在生成之前选择要暂存的文件和 hunk，之后的流程基于选择后的暂存区
+		if interactive {
+			finalModel, err := tea.NewProgram(ui.NewStageModel()).Run()
+			if err != nil {
+				return fmt.Errorf("UI 错误: %w", err)
+			}
+			stage := finalModel.(ui.StageModel)
+			if err := stage.Err(); err != nil {
+				return fmt.Errorf("Git错误: %w", err)
+			}
+			if !stage.Confirmed {
+				return ErrCancelled
 			}
 		}
 
-		// 1. 获取 Diff
-		diff, err := git.GetStagedDiff()
+		// 1. 获取 Diff，修改 HEAD 时比较的是 HEAD 的父提交和暂存区
+		// 指定 pathspec 时与 git commit -- <paths> 一致，比较 HEAD 和这些路径在工作区中的内容
+		spec := git.DiffSpec{Worktree: worktree || len(paths) > 0, Paths: paths}
+		var previousMessage string
+		if amend {
+			if !git.HasHead() {
+				return fmt.Errorf("当前仓库还没有可以修改的提交")
+			}
+			if spec.From, err = git.ParentOf("HEAD"); err != nil {
+				return fmt.Errorf("Git错误: %w", err)
+			}
+			previousMessage, _ = git.HeadMessage()
+			if remotes := git.RemoteBranchesContaining("HEAD"); len(remotes) > 0 {
+				fmt.Printf("⚠️ HEAD 已存在于远程分支 %s，修改后需要强制推送\n", strings.Join(remotes, ", "))
+			}
+		}
+
+		// 合并、cherry-pick、revert 和变基过程中的提交需要参考 git 准备的信息
+		var state git.RepoState
+		if !amend {
+			if state, err = repoState(); err != nil {
+				return err
+			}
+		}
+
+		diff, err := spec.Diff()
 		if err != nil {
-			fmt.Printf("❌ Git错误: %v\n", err)
-			return
+			return fmt.Errorf("Git错误: %w", err)
 		}
-		if diff == "" {
-			fmt.Println("⚠️ 暂存区为空，请先执行 git add")
-			return
+		// 合并的结果可以与 HEAD 完全相同，此时 git 仍然允许提交
+		if diff == "" && state.Operation != git.OpMerge {
+			switch {
+			case len(paths) > 0:
+				return fmt.Errorf("%w: 指定的路径没有任何变更", git.ErrEmptyDiff)
+			case worktree:
+				return fmt.Errorf("%w: 工作区没有任何变更", git.ErrEmptyDiff)
+			}
+			if amend {
+				return fmt.Errorf("%w: HEAD 和暂存区相对于父提交没有任何变更", git.ErrEmptyDiff)
+			}
+			return fmt.Errorf("%w: 暂存区为空，请先执行 git add", git.ErrEmptyDiff)
 		}
 
 		// 2. 初始化 LLM Client
-		// 这里为了演示方便，配置写死，之后可以用 Viper 做配置文件
-		client := llm.NewProvider(llm.ProviderConfig{
-			BaseURL:               cfg.BaseURL,
-			Path:                  cfg.Path,
-			APIKey:                cfg.APIKey,
-			Model:                 cfg.Model,
-			Language:              cfg.Language,
-			WithDescription:       cfg.WithDescription,
-			SubjectSeparateSymbol: cfg.SubjectSeparateSymbol,
-		}) // 3. 启动 UI 程序
-		// 创建一个带有超时的 Context
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		client := newClient(cfg)
+
+		// 3. 启动 UI 程序
+		// 每次请求的超时由 Provider 控制，界面中可以随时取消
+		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
-		model := ui.NewModel(ctx, client, diff)
+		opts := buildPromptOptions(cfg, diff, spec)
+		opts.PreviousMessage = previousMessage
+		applyRepoState(&opts, state)
+		postProcess := postProcessors(cfg, opts)
+		if state.Operation != git.OpNone {
+			postProcess = append(postProcess, llm.ApplyOperation(state.Reference, state.Conflicts))
+		}
+		client = llm.WithPostProcess(client, postProcess...)
+		alternatives := alternativeClients(cfg)
+		for i := range alternatives {
+			alternatives[i].Client = llm.WithPostProcess(alternatives[i].Client, postProcess...)
+		}
+
+		commitArgs := append(slices.Clone(cfg.CommitArgs), passthrough...)
+		if worktree && len(paths) == 0 {
+			commitArgs = append([]string{"--all"}, commitArgs...)
+		}
+		if amend {
+			commitArgs = append([]string{"--amend"}, commitArgs...)
+		}
+		if len(paths) > 0 {
+			commitArgs = append(append(commitArgs, "--"), paths...)
+		}
+		// 保存失败时界面还在运行，退出后再提示
+		var saveErr error
+		commit := func(msg string, coAuthors []string) (string, error) {
+			msg, err := applyTrailers(cfg, msg, coAuthors)
+			if err != nil {
+				return "", err
+			}
+			// 提交前先保存，钩子拒绝或进程中断时信息不会丢失
+			if _, err := repo.SaveMessage(msg); err != nil {
+				saveErr = err
+			}
+			return repo.Commit(msg, commitArgs...)
+		}
+
+		model := ui.NewModel(ctx, client, opts).
+			WithCoAuthors(git.CoAuthorCandidates(coAuthorHistorySize)).
+			WithAlternatives(alternatives).
+			WithDiff(diff).
+			WithCommit(commit)
 		p := tea.NewProgram(model)
 
-		// 运行 UI，它会阻塞直到用户按 Enter/Esc/Ctrl+C
+		// 运行 UI，它会阻塞直到提交成功或用户按 Esc/Ctrl+C
 		finalModel, err := p.Run()
 		if err != nil {
-			fmt.Printf("UI 错误: %v\n", err)
-			return
+			return fmt.Errorf("UI 错误: %w", err)
 		}
 
 		// 4. 处理最终结果
 		// 类型断言取回我们的 Model 数据
 		m, ok := finalModel.(ui.Model)
 		if !ok {
-			return
+			return nil
+		}
+		if saveErr != nil {
+			fmt.Fprintf(os.Stderr, "⚠️ 提交前保存提交信息失败: %v\n", saveErr)
+		}
+
+		if m.Committed {
+			fmt.Println(m.Output)
+			return nil
 		}
 
-		// 如果用户确认了提交
-		if m.Confirmed && m.Msg != "" {
-			git.Commit(m.Msg)
-		} else {
-			fmt.Println("\n🚫 已取消提交")
+		if m.Msg != "" {
+			msg, err := applyTrailers(cfg, m.Msg, m.CoAuthors())
+			if err != nil {
+				msg = m.Msg
+			}
+			if path, err := repo.SaveMessage(msg); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️ 保存提交信息失败，无法恢复: %v\n", err)
+			} else {
+				fmt.Printf("💾 提交信息已保存，可以执行 git commit -F %s 恢复\n", path)
+			}
+		}
+		// 生成失败或提交失败后退出时返回对应的错误，否则视为用户取消
+		if err := m.Err(); err != nil {
+			return err
 		}
+		return ErrCancelled
 	},
 }
 
+// needsRepo 判断命令是否需要在 git 仓库中运行，只处理补丁文件时不需要
+func needsRepo(cmd *cobra.Command) bool {
+	if !cmd.HasParent() {
+		return patchFile == ""
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		switch {
+		case c.Annotations[annotationNoRepo] != "",
+			c.Name() == "help", c.Name() == "completion", strings.HasPrefix(c.Name(), "__complete"):
+			return false
+		}
+	}
+	return true
+}
+
+// splitArgs 将参数分为 -- 之前的 pathspec 和 -- 之后透传给 git commit 的参数
+func splitArgs(cmd *cobra.Command, args []string) (paths, passthrough []string) {
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		return args[:dash], args[dash:]
+	}
+	return args, nil
+}
+
+// mergedCommitLimit 是合并时最多列给模型的被合并提交数量
+const mergedCommitLimit = 50
+
+// repoState 检测正在进行的合并、cherry-pick、revert 或变基，还有未解决的冲突时返回错误
+func repoState() (git.RepoState, error) {
+	state := repo.State()
+	if state.Operation == git.OpNone {
+		return state, nil
+	}
+	if files := repo.UnmergedFiles(); len(files) > 0 {
+		return state, fmt.Errorf("%s 还有未解决冲突的文件，请解决后执行 git add: %s", state.Operation, strings.Join(files, ", "))
+	}
+	fmt.Printf("🔀 检测到正在进行的 %s，将参考 git 准备的提交信息生成\n", state.Operation)
+	return state, nil
+}
+
+// applyRepoState 把正在进行的操作及其相关提交交给提示词
+func applyRepoState(opts *llm.PromptOptions, state git.RepoState) {
+	if state.Operation == git.OpNone {
+		return
+	}
+	opts.Operation = string(state.Operation)
+	opts.OperationMessage = state.Message
+	opts.Conflicts = state.Conflicts
+	if state.Operation != git.OpMerge {
+		return
+	}
+	for _, head := range state.Heads {
+		commits, err := git.RangeCommits("HEAD.." + head)
+		if err != nil {
+			continue
+		}
+		for _, c := range commits {
+			if len(opts.MergedCommits) == mergedCommitLimit {
+				return
+			}
+			opts.MergedCommits = append(opts.MergedCommits, c.Subject())
+		}
+	}
+}
+
+// postProcessors 返回生成结果的后处理：附加依赖变化列表、关联工单
+func postProcessors(cfg *config.Config, opts llm.PromptOptions) []func(string) string {
+	var postProcess []func(string) string
+	if cfg.DepsInBody && opts.Dependencies != "" {
+		postProcess = append(postProcess, llm.AppendBody("Dependencies:\n"+opts.Dependencies))
+	}
+	if len(opts.Tickets) > 0 {
+		postProcess = append(postProcess, llm.ApplyTickets(opts.Tickets, cfg.TicketPlacement))
+	}
+	return postProcess
+}
+
+// perOptionsClient 按每次请求的 PromptOptions 执行后处理，用于同一个 Client 为多个提交生成信息的命令
+type perOptionsClient struct {
+	llm.Client
+	cfg *config.Config
+}
+
+func (c perOptionsClient) GenerateCommitMessage(ctx context.Context, opts llm.PromptOptions) (string, error) {
+	return llm.WithPostProcess(c.Client, postProcessors(c.cfg, opts)...).GenerateCommitMessage(ctx, opts)
+}
+
+// readPatch 读取补丁文件，name 为 - 时从标准输入读取
+func readPatch(name string) (git.Patch, error) {
+	var data []byte
+	var err error
+	if name == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return git.Patch{}, fmt.Errorf("读取补丁失败: %w", err)
+	}
+	// 只支持带 diff --git 头的补丁，其他格式解析不出任何文件，生成的信息没有依据
+	if len(diff.Parse(string(data))) == 0 {
+		source := name
+		if name == "-" {
+			source = "标准输入"
+		}
+		return git.Patch{}, usageError{fmt.Errorf("%s 不是 git 格式的补丁，请使用 git diff、git format-patch 或 git diff --no-index 生成", source)}
+	}
+	return git.Patch{Text: string(data)}, nil
+}
+
+// draftMessage 只生成提交信息并输出到标准输出，提示信息输出到标准错误，
+// 用于补丁文件、git diff <rev> 等不能直接提交的变更
+func draftMessage(cfg *config.Config, src diffSource) error {
+	rawDiff, err := src.Diff()
+	if err != nil {
+		return fmt.Errorf("Git错误: %w", err)
+	}
+	if rawDiff == "" {
+		return fmt.Errorf("%w: 没有任何变更", git.ErrEmptyDiff)
+	}
+
+	opts := buildPromptOptions(cfg, rawDiff, src)
+	client := llm.WithPostProcess(newClient(cfg), postProcessors(cfg, opts)...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fmt.Fprintln(os.Stderr, "⏳ 正在生成提交信息...")
+	msg, err := client.GenerateCommitMessage(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("生成失败: %w", err)
+	}
+	fmt.Println(msg)
+	return nil
+}
+
+// newClient 根据配置创建 LLM Client
+func newClient(cfg *config.Config) llm.Client {
+	return llm.NewProvider(llm.ProviderConfig{
+		BaseURL: cfg.BaseURL,
+		Path:    cfg.Path,
+		APIKey:  cfg.APIKey,
+		Model:   cfg.Model,
+		Timeout: time.Duration(cfg.Timeout) * time.Second,
+	})
+}
+
+// alternativeClients 返回生成失败时可以切换的模型：当前提供商的其他常用模型，以及配置文件中的 profiles
+func alternativeClients(cfg *config.Config) []ui.Alternative {
+	var alternatives []ui.Alternative
+	for _, model := range providerModels[cfg.Provider] {
+		if model != cfg.Model {
+			alternatives = append(alternatives, ui.Alternative{
+				Name:   model,
+				Client: newClient(cfg.WithProfile(config.Profile{Model: model})),
+			})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Profiles)) {
+		profileCfg := cfg.WithProfile(cfg.Profiles[name])
+		alternatives = append(alternatives, ui.Alternative{
+			Name:   fmt.Sprintf("%s (%s)", name, profileCfg.Model),
+			Client: newClient(profileCfg),
+		})
+	}
+	return alternatives
+}
+
+// Execute 运行命令并输出错误，返回值可以用 ExitCode 转换为退出码
 func Execute() error {
-	return rootCmd.Execute()
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError{fmt.Errorf("%w\n运行 %s --help 查看用法", err, cmd.CommandPath())}
+	})
+
+	err := rootCmd.Execute()
+	if err != nil {
+		reportError(err)
+	}
+	return err
 }
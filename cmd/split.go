@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"aicommits/internal/config"
+	"aicommits/internal/diff"
+	"aicommits/internal/git"
+	"aicommits/internal/llm"
+	"aicommits/internal/ui"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "将暂存区的变更拆分为多个原子提交",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if err := cfg.RequireAPIKey(); err != nil {
+			return err
+		}
+
+		stagedDiff, err := git.GetStagedDiff()
+		if err != nil {
+			return fmt.Errorf("Git错误: %w", err)
+		}
+		if stagedDiff == "" {
+			return fmt.Errorf("%w: 暂存区为空，请先执行 git add", git.ErrEmptyDiff)
+		}
+
+		files := diff.Parse(stagedDiff)
+		units, refs := buildSplitUnits(files)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		opts := buildPromptOptions(cfg, stagedDiff, git.DiffSpec{})
+		model := ui.NewSplitModel(ctx, newClient(cfg), opts, units)
+		finalModel, err := tea.NewProgram(model).Run()
+		if err != nil {
+			return fmt.Errorf("UI 错误: %w", err)
+		}
+
+		m, ok := finalModel.(ui.SplitModel)
+		if !ok {
+			return nil
+		}
+		if err := m.Err(); err != nil {
+			return err
+		}
+		if !m.Confirmed {
+			return ErrCancelled
+		}
+
+		// 拆分方案通过 Chat 生成，不经过 Client 的后处理，这里为每个提交补上工单编号；
+		// 依赖变化摘要描述的是整个暂存区，不追加到每个提交中
+		for i, g := range m.Groups {
+			if len(opts.Tickets) > 0 {
+				g.Message = llm.ApplyTickets(opts.Tickets, cfg.TicketPlacement)(g.Message)
+			}
+			if m.Groups[i].Message, err = applyTrailers(cfg, g.Message, nil); err != nil {
+				return err
+			}
+		}
+		return commitGroups(files, refs, m.Groups, cfg.CommitArgs)
+	},
+}
+
+// unitRef 记录变更单元对应的文件和 hunk，hunk 为 -1 表示整个文件
+type unitRef struct {
+	file int
+	hunk int
+}
+
+// buildSplitUnits 将每个 hunk 作为一个变更单元，没有 hunk 的文件 (二进制、重命名等) 整体作为一个单元
+func buildSplitUnits(files []diff.File) ([]llm.SplitUnit, map[string]unitRef) {
+	var units []llm.SplitUnit
+	refs := map[string]unitRef{}
+
+	add := func(ref unitRef, label, patch string) {
+		id := strconv.Itoa(len(units) + 1)
+		units = append(units, llm.SplitUnit{ID: id, File: files[ref.file].Path(), Label: label, Patch: patch})
+		refs[id] = ref
+	}
+
+	for fi, f := range files {
+		if len(f.Hunks) == 0 {
+			add(unitRef{file: fi, hunk: -1}, f.Path(), f.String())
+			continue
+		}
+		for hi, h := range f.Hunks {
+			added, deleted := diff.File{Hunks: []diff.Hunk{h}}.Stat()
+			label := fmt.Sprintf("%s %s (+%d -%d)", f.Path(), hunkRange(h.Header), added, deleted)
+			add(unitRef{file: fi, hunk: hi}, label, h.String())
+		}
+	}
+	return units, refs
+}
+
+// hunkRange 取出 hunk 头部中 "@@ ... @@" 的部分
+func hunkRange(header string) string {
+	if end := strings.Index(header[2:], "@@"); end >= 0 {
+		return header[:end+4]
+	}
+	return header
+}
+
+// commitGroups 按拆分方案依次暂存并提交每一组变更
+// 只属于一个提交的文件直接从原暂存区中取出，跨提交的文件通过 git apply --cached 应用部分 hunk
+// 最后一个提交直接恢复原暂存区，确保所有变更都被提交；任何一步失败都会把暂存区恢复到未提交的剩余变更
+func commitGroups(files []diff.File, refs map[string]unitRef, groups []llm.SplitGroup, commitArgs []string) error {
+	origTree, err := git.WriteTree()
+	if err != nil {
+		return fmt.Errorf("无法保存暂存区状态: %w", err)
+	}
+
+	// 每个文件的变更分布在哪些提交中
+	fileGroups := map[int]map[int]bool{}
+	for gi, g := range groups {
+		for _, id := range g.Units {
+			ref := refs[id]
+			if fileGroups[ref.file] == nil {
+				fileGroups[ref.file] = map[int]bool{}
+			}
+			fileGroups[ref.file][gi] = true
+		}
+	}
+
+	for gi, g := range groups {
+		var err error
+		if gi == len(groups)-1 {
+			err = git.ReadTree(origTree)
+		} else {
+			err = stageGroup(files, refs, g, fileGroups, origTree)
+		}
+		if err == nil {
+			_, err = repo.Commit(g.Message, commitArgs...)
+		}
+
+		if err != nil {
+			if rbErr := git.ReadTree(origTree); rbErr != nil {
+				return fmt.Errorf("提交 %d 失败: %w\n恢复暂存区也失败了: %v", gi+1, err, rbErr)
+			}
+			return fmt.Errorf("提交 %d 失败，剩余变更已恢复到暂存区:\n%w", gi+1, err)
+		}
+
+		subject, _, _ := strings.Cut(g.Message, "\n")
+		fmt.Printf("✅ [%d/%d] %s\n", gi+1, len(groups), subject)
+	}
+	return nil
+}
+
+func stageGroup(files []diff.File, refs map[string]unitRef, g llm.SplitGroup, fileGroups map[int]map[int]bool, origTree string) error {
+	if err := git.ResetIndex(); err != nil {
+		return err
+	}
+
+	hunks := map[int][]int{}
+	for _, id := range g.Units {
+		ref := refs[id]
+		hunks[ref.file] = append(hunks[ref.file], ref.hunk)
+	}
+
+	for fi, selected := range hunks {
+		f := files[fi]
+		if len(fileGroups[fi]) == 1 {
+			for _, p := range []string{f.OldPath, f.NewPath} {
+				if p == diff.NullPath {
+					continue
+				}
+				if err := git.StageFromTree(origTree, p); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		sort.Ints(selected)
+		partial := diff.File{OldPath: f.OldPath, NewPath: f.NewPath, Header: f.Header}
+		for _, hi := range selected {
+			partial.Hunks = append(partial.Hunks, f.Hunks[hi])
+		}
+		if err := git.ApplyCached(partial.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(splitCmd)
+}
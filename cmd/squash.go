@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"aicommits/internal/config"
+	"aicommits/internal/git"
+	"aicommits/internal/llm"
+	"aicommits/internal/ui"
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var squashApply bool
+
+var squashMsgCmd = &cobra.Command{
+	Use:   "squash-msg <base>..HEAD",
+	Short: "为即将压缩的一段提交生成一条提交信息",
+	Long: `根据 <base>..HEAD 的整体 diff 和其中每个提交的标题，生成一条带有列表正文的 Conventional Commit。
+默认只输出提交信息；使用 --apply 时会在确认后执行 git reset --soft <base> 并提交。`,
+	Args: checkArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if err := cfg.RequireAPIKey(); err != nil {
+			return err
+		}
+
+		base, head, _ := strings.Cut(args[0], "..")
+		if head == "" {
+			head = "HEAD"
+		}
+		revRange := base + ".." + head
+
+		commits, err := git.RangeCommits(revRange)
+		if err != nil {
+			return fmt.Errorf("无法读取提交范围 %s: %w", revRange, err)
+		}
+		if len(commits) == 0 {
+			return fmt.Errorf("%w: %s 中没有提交", git.ErrEmptyDiff, revRange)
+		}
+
+		if squashApply {
+			if head != "HEAD" || !git.IsAncestor(base) {
+				return usageError{fmt.Errorf("--apply 只支持 <base>..HEAD，且 <base> 必须是 HEAD 的祖先")}
+			}
+			if repo.HasStagedChanges() {
+				return fmt.Errorf("暂存区中有未提交的变更，请先提交或取消暂存")
+			}
+		}
+
+		// 与 pr 相同，从公共祖先开始比较，<base> 之后新增的提交不属于这次压缩
+		mergeBase, err := git.MergeBase(base, head)
+		if err != nil {
+			return fmt.Errorf("Git错误: %w", err)
+		}
+		spec := git.DiffSpec{From: mergeBase, To: head}
+		rangeDiff, err := spec.Diff()
+		if err != nil {
+			return fmt.Errorf("Git错误: %w", err)
+		}
+
+		opts := buildPromptOptions(cfg, rangeDiff, spec)
+		opts.Convention = llm.ConventionConventional
+		opts.WithDescription = false
+		for _, c := range commits {
+			opts.SquashedCommits = append(opts.SquashedCommits, c.Subject())
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		postProcess := postProcessors(cfg, opts)
+		client := llm.WithPostProcess(newClient(cfg), postProcess...)
+
+		if !squashApply {
+			msg, err := client.GenerateCommitMessage(ctx, opts)
+			if err != nil {
+				return fmt.Errorf("生成失败: %w", err)
+			}
+			fmt.Println(msg)
+			return nil
+		}
+
+		alternatives := alternativeClients(cfg)
+		for i := range alternatives {
+			alternatives[i].Client = llm.WithPostProcess(alternatives[i].Client, postProcess...)
+		}
+		model := ui.NewModel(ctx, client, opts).
+			WithCoAuthors(git.CoAuthorCandidates(coAuthorHistorySize)).
+			WithAlternatives(alternatives).
+			WithDiff(rangeDiff)
+		finalModel, err := tea.NewProgram(model).Run()
+		if err != nil {
+			return fmt.Errorf("UI 错误: %w", err)
+		}
+		m, ok := finalModel.(ui.Model)
+		if !ok {
+			return nil
+		}
+		if err := m.Err(); err != nil {
+			return err
+		}
+		if !m.Confirmed || m.Msg == "" {
+			return ErrCancelled
+		}
+
+		msg, err := applyTrailers(cfg, m.Msg, m.CoAuthors())
+		if err != nil {
+			return err
+		}
+		if err := git.ResetSoft(base); err != nil {
+			return err
+		}
+		out, err := repo.Commit(msg, cfg.CommitArgs...)
+		if err != nil {
+			return fmt.Errorf("%w\n可以执行 git reset --soft ORIG_HEAD 恢复压缩前的提交", err)
+		}
+		fmt.Println(out)
+		return nil
+	},
+}
+
+func init() {
+	squashMsgCmd.Flags().BoolVar(&squashApply, "apply", false, "Soft-reset to <base> and commit with the generated message")
+	rootCmd.AddCommand(squashMsgCmd)
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"aicommits/internal/config"
+	"aicommits/internal/git"
+)
+
+// coAuthorHistorySize 是统计候选共同作者时读取的历史提交数量
+const coAuthorHistorySize = 500
+
+// applyTrailers 把配置中的 trailer、Signed-off-by 和选中的共同作者加入提交信息
+func applyTrailers(cfg *config.Config, msg string, coAuthors []string) (string, error) {
+	trailers := append([]string(nil), cfg.Trailers...)
+	if cfg.Signoff {
+		ident, err := git.Identity()
+		if err != nil {
+			return "", err
+		}
+		trailers = append(trailers, "Signed-off-by: "+ident)
+	}
+	for _, author := range coAuthors {
+		trailers = append(trailers, "Co-authored-by: "+author)
+	}
+	return git.InterpretTrailers(msg, trailers)
+}
@@ -14,8 +14,9 @@ var (
 )
 
 var versionCmd = &cobra.Command{
-	Use:   "version",
-	Short: "打印版本信息",
+	Use:         "version",
+	Annotations: map[string]string{annotationNoRepo: "true"},
+	Short:       "打印版本信息",
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Printf("aicommits %s (commit: %s, built at: %s)\n", version, commit, date)
 	},
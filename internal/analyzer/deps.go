@@ -0,0 +1,331 @@
+package analyzer
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"aicommits/internal/diff"
+)
+
+// lockfiles 是由工具生成、对理解变更没有帮助的锁文件
+var lockfiles = map[string]bool{
+	"go.sum":            true,
+	"package-lock.json": true,
+	"yarn.lock":         true,
+	"pnpm-lock.yaml":    true,
+	"bun.lockb":         true,
+	"Cargo.lock":        true,
+	"poetry.lock":       true,
+	"Pipfile.lock":      true,
+	"composer.lock":     true,
+	"Gemfile.lock":      true,
+}
+
+// IsLockfile 判断路径是否是锁文件
+func IsLockfile(p string) bool {
+	return lockfiles[path.Base(p)]
+}
+
+// DependencyChange 描述清单文件中一个依赖的变化
+type DependencyChange struct {
+	Manifest string // 清单文件路径
+	Name     string
+	Old      string // 变更前的版本，新增依赖为空
+	New      string // 变更后的版本，删除依赖为空
+}
+
+// Kind 返回变化类型: added、removed 或 changed
+func (c DependencyChange) Kind() string {
+	switch {
+	case c.Old == "":
+		return "added"
+	case c.New == "":
+		return "removed"
+	}
+	return "changed"
+}
+
+// String 返回一行可读的描述
+func (c DependencyChange) String() string {
+	switch c.Kind() {
+	case "added":
+		return fmt.Sprintf("add %s %s", c.Name, c.New)
+	case "removed":
+		return fmt.Sprintf("remove %s %s", c.Name, c.Old)
+	}
+	return fmt.Sprintf("bump %s %s -> %s", c.Name, c.Old, c.New)
+}
+
+// lineParser 解析清单文件中的一行，返回依赖名和版本；上下文行也会传入，用于跟踪所在的表或块
+type lineParser func(line string) (string, string, bool)
+
+// manifestParsers 按清单文件名为每个 hunk 创建解析器，header 是 hunk 的 @@ 头部。
+// 变更前后各用一个解析器，分别跟踪所在的表或块
+var manifestParsers = map[string]func(header string) lineParser{
+	"go.mod":           newGoModParser,
+	"package.json":     func(string) lineParser { return parsePackageJSONLine },
+	"Cargo.toml":       newCargoParser,
+	"requirements.txt": func(string) lineParser { return parseRequirementsLine },
+}
+
+// SummarizeDeps 从清单文件的 diff 中提取新增、删除和升降级的依赖
+func SummarizeDeps(files []diff.File) []DependencyChange {
+	var changes []DependencyChange
+	for _, f := range files {
+		newParser, ok := manifestParsers[path.Base(f.Path())]
+		if !ok {
+			continue
+		}
+
+		before, after := map[string]string{}, map[string]string{}
+		for _, h := range f.Hunks {
+			parseBefore, parseAfter := newParser(h.Header), newParser(h.Header)
+			for _, line := range h.Lines {
+				if line == "" {
+					continue
+				}
+				content := strings.TrimSpace(line[1:])
+				switch line[0] {
+				case '-':
+					if name, version, ok := parseBefore(content); ok {
+						before[name] = version
+					}
+				case '+':
+					if name, version, ok := parseAfter(content); ok {
+						after[name] = version
+					}
+				case ' ':
+					// 上下文行中的依赖没有变化，只用来更新所在的表或块
+					parseBefore(content)
+					parseAfter(content)
+				}
+			}
+		}
+
+		changes = append(changes, compareDeps(f.Path(), before, after)...)
+	}
+	return changes
+}
+
+func compareDeps(manifest string, before, after map[string]string) []DependencyChange {
+	var changes []DependencyChange
+	for name, newVersion := range after {
+		oldVersion, ok := before[name]
+		if ok && oldVersion == newVersion {
+			// 同一行因为缩进或注释变化出现在 diff 中，版本没有变化
+			continue
+		}
+		changes = append(changes, DependencyChange{Manifest: manifest, Name: name, Old: oldVersion, New: newVersion})
+	}
+	for name, oldVersion := range before {
+		if _, ok := after[name]; !ok {
+			changes = append(changes, DependencyChange{Manifest: manifest, Name: name, Old: oldVersion})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// FormatDeps 将依赖变化渲染为列表，涉及多个清单文件时注明所属文件
+func FormatDeps(changes []DependencyChange) string {
+	manifests := map[string]bool{}
+	for _, c := range changes {
+		manifests[c.Manifest] = true
+	}
+
+	var lines []string
+	for _, c := range changes {
+		line := "- " + c.String()
+		if len(manifests) > 1 {
+			line += " (" + c.Manifest + ")"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	goModRe        = regexp.MustCompile(`^(?:require\s+)?([^\s()]+)\s+(v\S+)`)
+	packageJSONRe  = regexp.MustCompile(`^"([^"]+)"\s*:\s*"([^"]+)",?$`)
+	jsonVersionRe  = regexp.MustCompile(`^([\^~><=]*\s*\d|\*$|latest$|workspace:|npm:|file:|link:)`)
+	cargoRe        = regexp.MustCompile(`^([A-Za-z0-9_-]+)\s*=\s*(?:"([^"]+)"|\{.*\bversion\s*=\s*"([^"]+)".*\})`)
+	requirementsRe = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*(?:\[[^\]]*\])?)\s*((?:[=<>!~]=?|===)\s*[^\s;#]+)?`)
+)
+
+// manifestFields 是 package.json / Cargo.toml 中不属于依赖的常见字段
+var manifestFields = map[string]bool{
+	"name": true, "version": true, "description": true, "main": true, "module": true, "types": true,
+	"license": true, "author": true, "homepage": true, "packageManager": true, "node": true,
+	"edition": true, "rust-version": true, "readme": true, "repository": true,
+}
+
+// goModDirectives 是 go.mod 中的指令，出现在行首时表示已经离开了之前的块
+var goModDirectives = map[string]bool{
+	"module": true, "go": true, "toolchain": true, "godebug": true, "require": true,
+	"exclude": true, "replace": true, "retract": true, "tool": true, "ignore": true,
+}
+
+// newGoModParser 只接受 require 指令和 require 块中的依赖，exclude、retract 等块中的行不算依赖
+func newGoModParser(header string) lineParser {
+	// git 默认把 hunk 之前最近的顶格行放在 @@ 之后，例如 "require (" 或 "exclude ("
+	var block string
+	if fields := strings.Fields(hunkContext(header)); len(fields) == 2 && fields[1] == "(" && goModDirectives[fields[0]] {
+		block = fields[0]
+	}
+
+	return func(line string) (string, string, bool) {
+		if strings.Contains(line, "=>") || strings.HasPrefix(line, "//") {
+			return "", "", false
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			return "", "", false
+		case fields[0] == ")":
+			block = ""
+			return "", "", false
+		case goModDirectives[fields[0]]:
+			block = ""
+			if len(fields) == 2 && fields[1] == "(" {
+				block = fields[0]
+				return "", "", false
+			}
+			if fields[0] != "require" {
+				return "", "", false
+			}
+		case block != "" && block != "require":
+			return "", "", false
+		}
+		return parseGoModLine(line)
+	}
+}
+
+func parseGoModLine(line string) (string, string, bool) {
+	m := goModRe.FindStringSubmatch(line)
+	if m == nil || goModDirectives[m[1]] {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+func parsePackageJSONLine(line string) (string, string, bool) {
+	m := packageJSONRe.FindStringSubmatch(line)
+	if m == nil || manifestFields[m[1]] || !jsonVersionRe.MatchString(m[2]) {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// cargoDepTables 是 Cargo.toml 中的依赖表，可以带 target.<cfg>. 前缀
+var cargoDepTables = []string{"dependencies", "dev-dependencies", "build-dependencies"}
+
+// newCargoParser 跟踪 hunk 中的表头，只接受依赖表中的依赖。
+// 表头不在 hunk 中时无法确定所在的表，沿用按行判断的结果
+func newCargoParser(header string) lineParser {
+	inDeps, dep := true, ""
+	if table, ok := tomlTable(hunkContext(header)); ok {
+		inDeps, dep = cargoDepsTable(table)
+	}
+
+	return func(line string) (string, string, bool) {
+		if table, ok := tomlTable(line); ok {
+			inDeps, dep = cargoDepsTable(table)
+			return "", "", false
+		}
+		if !inDeps {
+			return "", "", false
+		}
+		if dep != "" {
+			// [dependencies.serde] 形式的表中，版本写在 version 字段
+			if m := cargoRe.FindStringSubmatch(line); m != nil && m[1] == "version" && m[2] != "" {
+				return dep, m[2], true
+			}
+			return "", "", false
+		}
+		return parseCargoLine(line)
+	}
+}
+
+// cargoDepsTable 判断表名是否是依赖表，[dependencies.serde] 这样的单个依赖表同时返回依赖名
+func cargoDepsTable(table string) (bool, string) {
+	rest := table
+	switch {
+	case strings.HasPrefix(table, "workspace."):
+		rest = strings.TrimPrefix(table, "workspace.")
+		if rest != "dependencies" && !strings.HasPrefix(rest, "dependencies.") {
+			return false, ""
+		}
+	case strings.HasPrefix(table, "target."):
+		// target.'cfg(unix)'.dependencies 中的条件可能包含点号，从后往前查找依赖表
+		rest = ""
+		for _, t := range cargoDepTables {
+			if i := strings.LastIndex(table, "."+t); i >= 0 {
+				rest = table[i+1:]
+				break
+			}
+		}
+	}
+
+	name, dep, _ := strings.Cut(rest, ".")
+	if !slices.Contains(cargoDepTables, name) {
+		return false, ""
+	}
+	return true, strings.Trim(dep, `"'`)
+}
+
+// tomlTable 解析 TOML 的表头 [name] 或 [[name]]，返回表名
+func tomlTable(line string) (string, bool) {
+	if !strings.HasPrefix(line, "[") {
+		return "", false
+	}
+	end := strings.Index(line, "]")
+	if end < 0 {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimLeft(line[:end], "[")), true
+}
+
+// hunkContext 返回 hunk 头部 @@ ... @@ 之后的内容，即 git 找到的 hunk 之前最近的一行顶格内容
+func hunkContext(header string) string {
+	if !strings.HasPrefix(header, "@@") {
+		return ""
+	}
+	_, context, ok := strings.Cut(header[2:], "@@")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(context)
+}
+
+func parseCargoLine(line string) (string, string, bool) {
+	m := cargoRe.FindStringSubmatch(line)
+	if m == nil || manifestFields[m[1]] {
+		return "", "", false
+	}
+	version := m[2]
+	if version == "" {
+		version = m[3]
+	}
+	return m[1], version, true
+}
+
+func parseRequirementsLine(line string) (string, string, bool) {
+	if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+		return "", "", false
+	}
+	m := requirementsRe.FindStringSubmatch(line)
+	if m == nil {
+		return "", "", false
+	}
+	version := strings.ReplaceAll(m[2], " ", "")
+	if version == "" {
+		version = "*"
+	}
+	return m[1], version, true
+}
@@ -0,0 +1,352 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"maps"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"aicommits/internal/diff"
+)
+
+// Source 提供文件变更前后的内容，文件不存在时返回错误
+type Source interface {
+	Before(path string) ([]byte, error)
+	After(path string) ([]byte, error)
+	// ListBefore 列出变更前 dir 目录下的文件
+	ListBefore(dir string) ([]string, error)
+}
+
+// GoSummary 是 Go 代码变更的语义摘要
+type GoSummary struct {
+	Packages  []PackageChange
+	TestFiles []string // 变更的测试文件
+	TestOnly  bool     // 所有 Go 变更都在测试文件中
+	Files     []string // 变更已体现在摘要中的 .go 文件
+}
+
+// PackageChange 描述单个包中导出 API 的变化
+type PackageChange struct {
+	Dir     string
+	Name    string
+	New     bool     // 本次变更新建的包
+	Added   []string // 新增的导出声明
+	Removed []string // 删除的导出声明
+	Changed []Change // 签名或定义发生变化的导出声明
+}
+
+// Change 是同一声明变更前后的签名
+type Change struct {
+	Before string
+	After  string
+}
+
+// SummarizeGo 解析变更前后的 .go 文件，按包汇总导出函数、类型和方法的增删改
+func SummarizeGo(files []diff.File, src Source) GoSummary {
+	var summary GoSummary
+
+	dirs := map[string][]diff.File{}
+	var order []string
+	for _, f := range files {
+		p := f.Path()
+		if !strings.HasSuffix(p, ".go") {
+			continue
+		}
+		if strings.HasSuffix(p, "_test.go") {
+			summary.TestFiles = append(summary.TestFiles, p)
+			continue
+		}
+		dir := path.Dir(p)
+		if _, ok := dirs[dir]; !ok {
+			order = append(order, dir)
+		}
+		dirs[dir] = append(dirs[dir], f)
+	}
+	summary.TestOnly = len(order) == 0 && len(summary.TestFiles) > 0
+
+	for _, dir := range order {
+		change, covered := summarizePackage(dir, dirs[dir], src)
+		// 只有导出 API 发生变化的包才会出现在摘要中，仅修改内部实现的文件不算被覆盖
+		if change.New || len(change.Added)+len(change.Removed)+len(change.Changed) > 0 {
+			summary.Packages = append(summary.Packages, change)
+			summary.Files = append(summary.Files, covered...)
+		}
+	}
+	return summary
+}
+
+func summarizePackage(dir string, files []diff.File, src Source) (PackageChange, []string) {
+	change := PackageChange{Dir: dir}
+	before, after := map[string]string{}, map[string]string{}
+	existedBefore := false
+	// 有已存在的文件读取不到变更前的内容时 (例如来源是补丁)，无法判断包是否是新建的
+	unknownBefore := false
+	// 每个成功解析的文件中前后不同的导出声明
+	fileKeys := map[string][]string{}
+	var parsed []string
+
+	for _, f := range files {
+		var oldContent, newContent []byte
+		var oldErr, newErr error
+		if !f.IsNew() {
+			oldContent, oldErr = src.Before(f.OldPath)
+		}
+		if !f.IsDeleted() {
+			newContent, newErr = src.After(f.NewPath)
+		}
+		// 读取不到内容的文件不参与摘要，保留它的原始 diff
+		if oldErr != nil || newErr != nil {
+			unknownBefore = unknownBefore || oldErr != nil
+			continue
+		}
+
+		oldDecls, newDecls := map[string]string{}, map[string]string{}
+		oldOK, newOK := true, true
+		if !f.IsNew() {
+			name, ok := collectDecls(f.OldPath, oldContent, oldDecls)
+			oldOK = ok
+			existedBefore = existedBefore || ok
+			if change.Name == "" {
+				change.Name = name
+			}
+		}
+		if !f.IsDeleted() {
+			name, ok := collectDecls(f.NewPath, newContent, newDecls)
+			newOK = ok
+			if ok {
+				change.Name = name
+			}
+		}
+		maps.Copy(before, oldDecls)
+		maps.Copy(after, newDecls)
+
+		// 解析失败的文件不算作已摘要，保留它的原始 diff
+		if oldOK && newOK {
+			parsed = append(parsed, f.Path())
+			fileKeys[f.Path()] = changedKeys(oldDecls, newDecls)
+		}
+	}
+
+	if !existedBefore && !unknownBefore {
+		change.New = !hasGoFiles(src, dir)
+	}
+
+	changed := map[string]bool{}
+	for _, key := range sortedKeys(after) {
+		old, ok := before[key]
+		switch {
+		case !ok:
+			change.Added = append(change.Added, after[key])
+		case old != after[key]:
+			change.Changed = append(change.Changed, Change{Before: old, After: after[key]})
+		default:
+			continue
+		}
+		changed[key] = true
+	}
+	for _, key := range sortedKeys(before) {
+		if _, ok := after[key]; !ok {
+			change.Removed = append(change.Removed, before[key])
+			changed[key] = true
+		}
+	}
+
+	// 只有带来了导出 API 变化的文件才算被摘要覆盖；只修改内部实现，
+	// 或只是在包内文件之间移动声明的文件保留原始 diff
+	var covered []string
+	for _, p := range parsed {
+		if slices.ContainsFunc(fileKeys[p], func(key string) bool { return changed[key] }) {
+			covered = append(covered, p)
+		}
+	}
+	return change, covered
+}
+
+// changedKeys 返回同一文件变更前后不同的声明
+func changedKeys(before, after map[string]string) []string {
+	var keys []string
+	for key, sig := range after {
+		if old, ok := before[key]; !ok || old != sig {
+			keys = append(keys, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func hasGoFiles(src Source, dir string) bool {
+	names, err := src.ListBefore(dir)
+	if err != nil {
+		return false
+	}
+	for _, name := range names {
+		if strings.HasSuffix(name, ".go") {
+			return true
+		}
+	}
+	return false
+}
+
+// collectDecls 将文件中的导出声明写入 decls，key 区分函数、方法和类型，value 是用于展示的签名
+func collectDecls(filename string, content []byte, decls map[string]string) (string, bool) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, content, parser.SkipObjectResolution)
+	if err != nil {
+		return "", false
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			key := "func " + d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				recv := receiverName(d.Recv.List[0].Type)
+				if !ast.IsExported(recv) {
+					continue
+				}
+				key = "method " + recv + "." + d.Name.Name
+			}
+			sig := *d
+			sig.Body, sig.Doc = nil, nil
+			decls[key] = render(fset, &sig)
+
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if ts.Name.IsExported() {
+					collectTypeDecls(fset, ts, decls)
+				}
+			}
+		}
+	}
+	return file.Name.Name, true
+}
+
+// collectTypeDecls 记录类型声明，结构体字段和接口方法单独记录，以便摘要只列出变化的成员
+func collectTypeDecls(fset *token.FileSet, ts *ast.TypeSpec, decls map[string]string) {
+	name := ts.Name.Name
+	var members *ast.FieldList
+	switch t := ts.Type.(type) {
+	case *ast.StructType:
+		decls["type "+name] = "type " + name + " struct"
+		members = t.Fields
+	case *ast.InterfaceType:
+		decls["type "+name] = "type " + name + " interface"
+		members = t.Methods
+	default:
+		decls["type "+name] = "type " + render(fset, ts)
+		return
+	}
+
+	for _, field := range members.List {
+		typ := render(fset, field.Type)
+		if len(field.Names) == 0 {
+			// 嵌入字段以类型名作为成员名
+			embedded := receiverName(field.Type)
+			if ast.IsExported(embedded) {
+				decls["member "+name+"."+embedded] = name + " embeds " + typ
+			}
+			continue
+		}
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			if _, ok := field.Type.(*ast.FuncType); ok {
+				decls["member "+name+"."+ident.Name] = name + "." + ident.Name + strings.TrimPrefix(typ, "func")
+			} else {
+				decls["member "+name+"."+ident.Name] = name + "." + ident.Name + " " + typ
+			}
+		}
+	}
+}
+
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+func render(fset *token.FileSet, node any) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	// 多行的定义压缩为一行，避免摘要过长
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Covers 表示 path 的变更已经体现在摘要中
+func (s GoSummary) Covers(p string) bool {
+	for _, f := range s.Files {
+		if f == p {
+			return true
+		}
+	}
+	return false
+}
+
+// String 将摘要渲染为提供给模型的文本，没有可描述的内容时返回空字符串
+func (s GoSummary) String() string {
+	var b strings.Builder
+	for _, pkg := range s.Packages {
+		fmt.Fprintf(&b, "package %s (%s)", pkg.Name, pkg.Dir)
+		if pkg.New {
+			b.WriteString(" [new package]")
+		}
+		b.WriteByte('\n')
+		for _, d := range pkg.Added {
+			fmt.Fprintf(&b, "  + %s\n", d)
+		}
+		for _, d := range pkg.Removed {
+			fmt.Fprintf(&b, "  - %s\n", d)
+		}
+		for _, c := range pkg.Changed {
+			fmt.Fprintf(&b, "  ~ %s\n    => %s\n", c.Before, c.After)
+		}
+	}
+
+	if len(s.TestFiles) > 0 {
+		if s.TestOnly {
+			b.WriteString("test-only change, ")
+		}
+		fmt.Fprintf(&b, "test files changed: %s\n", strings.Join(s.TestFiles, ", "))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
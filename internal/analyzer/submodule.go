@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"aicommits/internal/diff"
+)
+
+// SubmoduleChange 是子模块指针的一次移动
+type SubmoduleChange struct {
+	Path     string
+	From, To string   // 变更前后的提交，新增子模块时 From 为空，删除时 To 为空
+	Commits  []string // From..To 之间的提交 ("<hash> <subject>")，由新到旧
+	Rewound  bool     // 指针移回了更早的提交，Commits 是被移除的提交
+	LogErr   error    // 读取子模块提交记录失败，通常是因为子模块没有检出
+}
+
+// SubmoduleLog 读取子模块 path 中 from..to 之间的提交
+type SubmoduleLog func(path, from, to string) ([]string, error)
+
+// SummarizeSubmodules 找出 diff 中子模块指针的变化，并通过 log 读取两次指针之间的提交
+func SummarizeSubmodules(files []diff.File, log SubmoduleLog) []SubmoduleChange {
+	var changes []SubmoduleChange
+	for _, f := range files {
+		from, to, ok := submodulePointers(f)
+		if !ok {
+			continue
+		}
+		c := SubmoduleChange{Path: f.Path(), From: from, To: to}
+		if from != "" && to != "" {
+			c.Commits, c.LogErr = log(c.Path, from, to)
+			if c.LogErr == nil && len(c.Commits) == 0 {
+				// 新指针是旧指针的祖先时，from..to 为空，改为列出被移除的提交
+				if back, err := log(c.Path, to, from); err == nil && len(back) > 0 {
+					c.Commits, c.Rewound = back, true
+				}
+			}
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// submodulePointers 从子模块的 diff ("-Subproject commit <sha>" / "+Subproject commit <sha>") 中读取前后的提交
+func submodulePointers(f diff.File) (from, to string, ok bool) {
+	for _, h := range f.Hunks {
+		for _, line := range h.Lines {
+			if len(line) == 0 {
+				continue
+			}
+			sha, found := strings.CutPrefix(line[1:], "Subproject commit ")
+			if !found {
+				continue
+			}
+			// 子模块工作区有未提交的修改时带有 -dirty 后缀
+			sha = strings.TrimSuffix(sha, "-dirty")
+			switch line[0] {
+			case '-':
+				from, ok = sha, true
+			case '+':
+				to, ok = sha, true
+			}
+		}
+	}
+	return from, to, ok
+}
+
+// FormatSubmodules 将子模块变化格式化为列表，每个子模块下缩进列出其间的提交
+func FormatSubmodules(changes []SubmoduleChange) string {
+	var lines []string
+	for _, c := range changes {
+		switch {
+		case c.From == "":
+			lines = append(lines, fmt.Sprintf("- %s: added at %s", c.Path, shortSHA(c.To)))
+			continue
+		case c.To == "":
+			lines = append(lines, fmt.Sprintf("- %s: removed (was %s)", c.Path, shortSHA(c.From)))
+			continue
+		}
+
+		line := fmt.Sprintf("- %s: %s → %s", c.Path, shortSHA(c.From), shortSHA(c.To))
+		switch {
+		case c.LogErr != nil:
+			line += " (submodule is not checked out, commit log unavailable)"
+		case c.Rewound:
+			line += " (moved back, these commits are removed)"
+		}
+		lines = append(lines, line)
+		for _, commit := range c.Commits {
+			lines = append(lines, "  - "+commit)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,188 @@
+package changelog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"aicommits/internal/conventional"
+)
+
+// 分组的显示顺序，遵循 Keep a Changelog 的分类
+var sectionOrder = []string{"Breaking Changes", "Added", "Changed", "Deprecated", "Removed", "Fixed", "Security", "Other"}
+
+// typeSections 将提交类型映射到 Keep a Changelog 的分类，未列出的类型只在 includeAll 时归入 Other
+var typeSections = map[string]string{
+	"feat":     "Added",
+	"fix":      "Fixed",
+	"perf":     "Changed",
+	"refactor": "Changed",
+	"revert":   "Changed",
+	"security": "Security",
+}
+
+// Entry 是变更日志中的一条记录
+type Entry struct {
+	Scope       string
+	Description string
+	Hash        string
+}
+
+// Release 是变更日志中一个版本的所有记录
+type Release struct {
+	Version string // 版本号，未发布时为 Unreleased
+	Date    string // 发布日期 (YYYY-MM-DD)，未发布时为空
+	Groups  map[string][]Entry
+}
+
+// Commit 是生成变更日志所需的提交信息
+type Commit struct {
+	Hash    string
+	Message string
+}
+
+// Build 按类型和 scope 对提交分组，不符合 Conventional Commits 的提交只在 includeAll 时归入 Other
+// 破坏性变更除了出现在所属分类中，还会单独列在 Breaking Changes 中
+func Build(version, date string, commits []Commit, includeAll bool) Release {
+	release := Release{Version: version, Date: date, Groups: map[string][]Entry{}}
+	for _, c := range commits {
+		parsed, ok := conventional.Parse(c.Message)
+		if !ok {
+			if includeAll {
+				subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+				release.Groups["Other"] = append(release.Groups["Other"], Entry{Description: subject, Hash: c.Hash})
+			}
+			continue
+		}
+
+		entry := Entry{Scope: parsed.Scope, Description: parsed.Description, Hash: c.Hash}
+		if parsed.Breaking {
+			breaking := entry
+			if parsed.BreakingNote != "" {
+				breaking.Description = parsed.BreakingNote
+			}
+			release.Groups["Breaking Changes"] = append(release.Groups["Breaking Changes"], breaking)
+		}
+
+		section, ok := typeSections[parsed.Type]
+		if !ok {
+			if !includeAll {
+				continue
+			}
+			section = "Other"
+		}
+		release.Groups[section] = append(release.Groups[section], entry)
+	}
+
+	// 同一分类中按 scope 聚在一起，没有 scope 的排在最前，其余保持提交顺序
+	for _, entries := range release.Groups {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Scope < entries[j].Scope
+		})
+	}
+	return release
+}
+
+// IsEmpty 表示没有任何可以写入变更日志的记录
+func (r Release) IsEmpty() bool {
+	return len(r.Groups) == 0
+}
+
+// Heading 返回版本标题，例如 "## [1.2.0] - 2024-01-01"
+func (r Release) Heading() string {
+	if r.Date == "" {
+		return fmt.Sprintf("## [%s]", r.Version)
+	}
+	return fmt.Sprintf("## [%s] - %s", r.Version, r.Date)
+}
+
+// Render 渲染为 Keep a Changelog 格式的一个版本小节
+func (r Release) Render() string {
+	var b strings.Builder
+	b.WriteString(r.Heading() + "\n")
+	for _, section := range sectionOrder {
+		entries := r.Groups[section]
+		if len(entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", section)
+		for _, e := range entries {
+			b.WriteString("- ")
+			if e.Scope != "" {
+				fmt.Fprintf(&b, "**%s:** ", e.Scope)
+			}
+			b.WriteString(e.Description)
+			if e.Hash != "" {
+				fmt.Fprintf(&b, " (%s)", shortHash(e.Hash))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+const defaultHeader = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/).
+`
+
+// Prepend 将版本小节插入到已有的变更日志中
+// 新的小节放在第一个已发布版本之前 (Unreleased 始终保持在最前)；已有同名版本时替换该小节
+// 内容为空时生成默认的文件头
+func Prepend(existing, section, version string) string {
+	if strings.TrimSpace(existing) == "" {
+		existing = defaultHeader
+	}
+	section = strings.TrimRight(section, "\n") + "\n"
+
+	lines := strings.Split(existing, "\n")
+	insertAt, replaceEnd := len(lines), -1
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "## ") {
+			continue
+		}
+		if strings.HasPrefix(line, "## ["+version+"]") {
+			insertAt, replaceEnd = i, nextHeading(lines, i+1)
+			break
+		}
+		if strings.HasPrefix(line, "## [Unreleased]") {
+			continue
+		}
+		insertAt = i
+		break
+	}
+	if replaceEnd < 0 {
+		replaceEnd = insertAt
+	}
+
+	head := strings.TrimRight(strings.Join(lines[:insertAt], "\n"), "\n")
+	tail := strings.TrimSpace(strings.Join(lines[replaceEnd:], "\n"))
+
+	result := section
+	if head != "" {
+		result = head + "\n\n" + result
+	}
+	if tail != "" {
+		result += "\n" + tail + "\n"
+	}
+	return result
+}
+
+// nextHeading 返回从 start 开始的下一个版本标题所在的行，没有时返回行数
+func nextHeading(lines []string, start int) int {
+	for i := start; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "## ") {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -1,9 +1,11 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 
 	"github.com/spf13/viper"
 )
@@ -18,8 +20,71 @@ type Config struct {
 	Language              string `mapstructure:"language"`
 	WithDescription       bool   `mapstructure:"with_description"`
 	SubjectSeparateSymbol string `mapstructure:"subject_separate_symbol"`
+	Examples              int    `mapstructure:"examples"`         // 作为示例的历史提交数量，0 表示不使用
+	ExamplesMode          string `mapstructure:"examples_mode"`    // recent: 最近的提交; similar: 修改路径最相似的提交
+	AutoStyle             bool   `mapstructure:"auto_style"`       // 根据历史提交自动识别风格约定和语言
+	MaxDiffSize           int    `mapstructure:"max_diff_size"`    // 发送给模型的 diff 最大字节数，0 表示不限制
+	DepsInBody            bool   `mapstructure:"deps_in_body"`     // 在提交正文末尾附上依赖变化列表
+	TicketPattern         string `mapstructure:"ticket_pattern"`   // 从分支名提取工单编号的正则，为空时匹配 PROJ-1234 形式
+	TicketPlacement       string `mapstructure:"ticket_placement"` // 工单编号的位置: prefix (标题开头) 或 trailer (Refs: 脚注)，为空时不添加
+	Signoff               bool   `mapstructure:"signoff"`          // 总是添加 Signed-off-by trailer
+	Timeout               int    `mapstructure:"timeout"`          // 单次请求的超时秒数，0 表示使用默认值 (60 秒)
+
+	// Trailers 每次提交都添加的自定义 trailer，例如 "Reviewed-by: Name <mail>"
+	Trailers []string `mapstructure:"trailers"`
+
+	// CommitArgs 每次执行 git commit 时附加的参数，例如 ["-S", "--no-verify"]
+	CommitArgs []string `mapstructure:"commit_args"`
+
+	// Scopes 路径前缀 → scope 的映射表，例如 internal/llm: llm
+	Scopes map[string]string `mapstructure:"scopes"`
+
+	// Profiles 生成失败时可以切换的其他模型配置，按名称索引
+	Profiles map[string]Profile `mapstructure:"profiles"`
+}
+
+// Profile 是一组模型相关的配置，未设置的字段沿用主配置
+type Profile struct {
+	Provider string `mapstructure:"provider"`
+	APIKey   string `mapstructure:"api_key"`
+	Model    string `mapstructure:"model"`
+	BaseURL  string `mapstructure:"base_url"`
+	Path     string `mapstructure:"path"`
+}
+
+// WithProfile 返回应用了 profile 之后的配置副本
+func (c *Config) WithProfile(p Profile) *Config {
+	cfg := *c
+	if p.Provider != "" {
+		cfg.Provider = p.Provider
+	}
+	if p.APIKey != "" {
+		cfg.APIKey = p.APIKey
+	}
+	if p.Model != "" {
+		cfg.Model = p.Model
+	}
+	if p.BaseURL != "" {
+		cfg.BaseURL = p.BaseURL
+	}
+	if p.Path != "" {
+		cfg.Path = p.Path
+	}
+	return &cfg
 }
 
+// 可以用 errors.Is 判断的错误类别
+var (
+	ErrInvalidConfig = errors.New("配置加载失败")
+	ErrMissingAPIKey = errors.New("未检测到 API Key，请先运行: aicommits config")
+)
+
+// 示例提交的挑选方式
+const (
+	ExamplesModeRecent  = "recent"
+	ExamplesModeSimilar = "similar"
+)
+
 // init 初始化 Viper 配置
 func init() {
 	// 配置文件名 (不带后缀)
@@ -42,16 +107,38 @@ func Load() (*Config, error) {
 				Path:    "/chat/completions",
 			}, nil
 		}
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
 	}
 
 	var cfg Config
 	if err := viper.Unmarshal(&cfg); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	if err := ValidateTicketPattern(cfg.TicketPattern); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
 	}
 	return &cfg, nil
 }
 
+// ValidateTicketPattern 检查 ticket_pattern 是否是合法的正则表达式，为空时使用默认规则
+func ValidateTicketPattern(pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return fmt.Errorf("ticket_pattern 不是合法的正则表达式: %w", err)
+	}
+	return nil
+}
+
+// RequireAPIKey 在未配置 API Key 时返回 ErrMissingAPIKey
+func (c *Config) RequireAPIKey() error {
+	if c.APIKey == "" {
+		return ErrMissingAPIKey
+	}
+	return nil
+}
+
 // Set 更新某一项配置并保存到磁盘
 func Set(key, value string) error {
 	viper.Set(key, value)
@@ -105,11 +192,31 @@ func GetPrintable() string {
 		key = "(未设置)"
 	}
 
+	examplesMode := viper.GetString("examples_mode")
+	if examplesMode == "" {
+		examplesMode = ExamplesModeRecent
+	}
+
 	return fmt.Sprintf(`
 Current Configuration:
   Provider: %s
   Model:    %s
   API Key:  %s
   Subject Separate Symbol: %s
-`, viper.GetString("provider"), viper.GetString("model"), key, viper.GetString("subject_separate_symbol"))
+  Examples: %d (%s)
+  Auto Style: %t
+`, viper.GetString("provider"), viper.GetString("model"), key, viper.GetString("subject_separate_symbol"),
+		viper.GetInt("examples"), examplesMode, viper.GetBool("auto_style"))
+}
+
+// PromptDirs 返回提示词模板的查找目录，仓库级 (<repo>/.aicommits/prompts) 优先于全局 (~/.aicommits/prompts)
+func PromptDirs(repoRoot string) []string {
+	var dirs []string
+	if repoRoot != "" {
+		dirs = append(dirs, filepath.Join(repoRoot, ".aicommits", "prompts"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".aicommits", "prompts"))
+	}
+	return dirs
 }
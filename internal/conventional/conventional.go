@@ -0,0 +1,47 @@
+package conventional
+
+import (
+	"regexp"
+	"strings"
+)
+
+// headerRe 拆分 Conventional Commits 标题: type(scope)!: description
+var headerRe = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^)]*)\))?(!)?: (.+)$`)
+
+// Commit 是解析后的 Conventional Commit
+type Commit struct {
+	Type         string
+	Scope        string
+	Breaking     bool   // 标题中带 ! 或正文中有 BREAKING CHANGE 脚注
+	Description  string // 标题中冒号之后的部分
+	Body         string // 标题之后的内容
+	BreakingNote string // BREAKING CHANGE 脚注的内容
+}
+
+// Parse 解析提交信息，标题不符合 Conventional Commits 格式时返回 false
+func Parse(msg string) (Commit, bool) {
+	msg = strings.TrimSpace(msg)
+	header, body, _ := strings.Cut(msg, "\n")
+
+	m := headerRe.FindStringSubmatch(strings.TrimSpace(header))
+	if m == nil {
+		return Commit{}, false
+	}
+
+	c := Commit{
+		Type:        strings.ToLower(m[1]),
+		Scope:       m[2],
+		Breaking:    m[3] == "!",
+		Description: strings.TrimSpace(m[4]),
+		Body:        strings.TrimSpace(body),
+	}
+	for _, line := range strings.Split(c.Body, "\n") {
+		for _, token := range []string{"BREAKING CHANGE:", "BREAKING-CHANGE:"} {
+			if note, ok := strings.CutPrefix(line, token); ok {
+				c.Breaking = true
+				c.BreakingNote = strings.TrimSpace(note)
+			}
+		}
+	}
+	return c, true
+}
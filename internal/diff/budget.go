@@ -0,0 +1,56 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Shrink 在 diff 文本超过 budget 个字节时省略部分文件的 hunk，使其尽量不超过预算
+// 优先省略 dropFirst 返回 true 的文件 (例如已有语义摘要的文件)，其余文件按 hunk 大小从大到小省略
+// 被省略的文件仍保留头部，并附上一行说明，让模型知道它发生了变更
+// budget <= 0 表示不限制
+func Shrink(files []File, budget int, dropFirst func(File) bool) string {
+	text := String(files)
+	if budget <= 0 || len(text) <= budget {
+		return text
+	}
+
+	shrunk := make([]File, len(files))
+	copy(shrunk, files)
+
+	order := make([]int, len(shrunk))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		fa, fb := shrunk[order[a]], shrunk[order[b]]
+		if pa, pb := dropFirst != nil && dropFirst(fa), dropFirst != nil && dropFirst(fb); pa != pb {
+			return pa
+		}
+		return len(fa.String()) > len(fb.String())
+	})
+
+	size := len(text)
+	for _, i := range order {
+		if size <= budget {
+			break
+		}
+		before := len(shrunk[i].String())
+		shrunk[i] = shrunk[i].WithoutHunks()
+		size -= before - len(shrunk[i].String())
+	}
+	return String(shrunk)
+}
+
+// WithoutHunks 返回省略了所有 hunk 的文件变更，头部会附上一行说明被省略的行数
+func (f File) WithoutHunks() File {
+	if len(f.Hunks) == 0 {
+		return f
+	}
+	added, deleted := f.Stat()
+	header := make([]string, len(f.Header), len(f.Header)+1)
+	copy(header, f.Header)
+	f.Header = append(header, fmt.Sprintf("(%d hunks omitted: +%d -%d lines)", len(f.Hunks), added, deleted))
+	f.Hunks = nil
+	return f
+}
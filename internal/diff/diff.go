@@ -0,0 +1,149 @@
+package diff
+
+import (
+	"strings"
+)
+
+// NullPath 是 diff 中表示文件不存在的路径
+const NullPath = "/dev/null"
+
+// File 是 unified diff 中单个文件的变更
+type File struct {
+	OldPath string   // 变更前的路径，新增文件为 NullPath
+	NewPath string   // 变更后的路径，删除文件为 NullPath
+	Header  []string // diff --git 到第一个 @@ 之前的所有行
+	Hunks   []Hunk
+}
+
+// Hunk 是文件中以 @@ 开头的一段变更
+type Hunk struct {
+	Header string   // @@ -a,b +c,d @@ 行
+	Lines  []string // 以 ' '、'+'、'-' 或 '\' 开头的内容行
+}
+
+// Path 返回文件当前的路径，删除文件返回原路径
+func (f File) Path() string {
+	if f.NewPath == NullPath {
+		return f.OldPath
+	}
+	return f.NewPath
+}
+
+// IsNew 表示文件是新增的
+func (f File) IsNew() bool {
+	return f.OldPath == NullPath
+}
+
+// IsDeleted 表示文件被删除
+func (f File) IsDeleted() bool {
+	return f.NewPath == NullPath
+}
+
+// Stat 返回新增和删除的行数
+func (f File) Stat() (added, deleted int) {
+	for _, h := range f.Hunks {
+		for _, line := range h.Lines {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				added++
+			case strings.HasPrefix(line, "-"):
+				deleted++
+			}
+		}
+	}
+	return added, deleted
+}
+
+// String 将文件变更还原为 unified diff 文本
+func (f File) String() string {
+	var b strings.Builder
+	for _, line := range f.Header {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	for _, h := range f.Hunks {
+		b.WriteString(h.String())
+	}
+	return b.String()
+}
+
+// String 将 hunk 还原为 unified diff 文本
+func (h Hunk) String() string {
+	var b strings.Builder
+	b.WriteString(h.Header)
+	b.WriteByte('\n')
+	for _, line := range h.Lines {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Parse 解析 git diff 输出的 unified diff 文本
+func Parse(text string) []File {
+	var (
+		files []File
+		cur   *File
+	)
+
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, File{})
+			cur = &files[len(files)-1]
+			cur.Header = append(cur.Header, line)
+			cur.OldPath, cur.NewPath = parseGitHeader(line)
+		case cur == nil:
+			// diff --git 之前的内容 (例如邮件补丁的头部) 直接忽略
+			continue
+		case strings.HasPrefix(line, "@@"):
+			cur.Hunks = append(cur.Hunks, Hunk{Header: line})
+		case len(cur.Hunks) > 0:
+			h := &cur.Hunks[len(cur.Hunks)-1]
+			h.Lines = append(h.Lines, line)
+		default:
+			cur.Header = append(cur.Header, line)
+			switch {
+			case strings.HasPrefix(line, "--- "):
+				cur.OldPath = trimPathPrefix(strings.TrimPrefix(line, "--- "), "a/")
+			case strings.HasPrefix(line, "+++ "):
+				cur.NewPath = trimPathPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+			case strings.HasPrefix(line, "new file mode"):
+				cur.OldPath = NullPath
+			case strings.HasPrefix(line, "deleted file mode"):
+				cur.NewPath = NullPath
+			case strings.HasPrefix(line, "rename from "):
+				cur.OldPath = strings.TrimPrefix(line, "rename from ")
+			case strings.HasPrefix(line, "rename to "):
+				cur.NewPath = strings.TrimPrefix(line, "rename to ")
+			}
+		}
+	}
+	return files
+}
+
+// String 将多个文件变更还原为 unified diff 文本
+func String(files []File) string {
+	var b strings.Builder
+	for _, f := range files {
+		b.WriteString(f.String())
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// parseGitHeader 从 "diff --git a/x b/x" 中取出路径，路径含空格时以 --- / +++ 行为准
+func parseGitHeader(line string) (string, string) {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if i := strings.Index(rest, " b/"); i >= 0 {
+		return trimPathPrefix(rest[:i], "a/"), rest[i+3:]
+	}
+	return rest, rest
+}
+
+func trimPathPrefix(p, prefix string) string {
+	p = strings.TrimSuffix(p, "\t")
+	if p == NullPath {
+		return p
+	}
+	return strings.TrimPrefix(p, prefix)
+}
@@ -0,0 +1,94 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// DiffSpec 描述一次 diff 的两端
+// 零值表示 HEAD 与暂存区之间的变更，即 git diff --cached
+type DiffSpec struct {
+	From     string   // 变更前的版本，为空时表示 HEAD
+	To       string   // 变更后的版本，为空时表示暂存区
+	Worktree bool     // 变更后是工作区而不是暂存区 (git diff <From>)，此时忽略 To
+	Paths    []string // 只包含匹配这些 pathspec 的文件，为空时包含所有文件
+}
+
+// CommitSpec 返回单个提交引入的变更，根提交与空 tree 比较
+func CommitSpec(rev string) (DiffSpec, error) {
+	parent, err := ParentOf(rev)
+	if err != nil {
+		return DiffSpec{}, err
+	}
+	return DiffSpec{From: parent, To: rev}, nil
+}
+
+// Diff 返回 unified diff 文本
+func (s DiffSpec) Diff() (string, error) {
+	return s.run("--diff-algorithm=minimal")
+}
+
+// Stat 返回变更的统计信息 (--stat)
+func (s DiffSpec) Stat() (string, error) {
+	return s.run("--stat")
+}
+
+// Files 返回变更的文件路径
+func (s DiffSpec) Files() ([]string, error) {
+	output, err := s.run("--name-only")
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(output), nil
+}
+
+// Before 读取文件变更前的内容
+func (s DiffSpec) Before(path string) ([]byte, error) {
+	return FileAt(s.from(), path)
+}
+
+// After 读取文件变更后的内容，path 相对于仓库根目录
+func (s DiffSpec) After(path string) ([]byte, error) {
+	if s.Worktree {
+		return os.ReadFile(filepath.Join(TopLevel(), path))
+	}
+	return FileAt(s.To, path)
+}
+
+// ListBefore 列出变更前 dir 目录下的文件
+func (s DiffSpec) ListBefore(dir string) ([]string, error) {
+	return ListFiles(s.from(), dir)
+}
+
+func (s DiffSpec) from() string {
+	if s.From == "" {
+		return "HEAD"
+	}
+	return s.From
+}
+
+func (s DiffSpec) run(flag string) (string, error) {
+	args := []string{"diff", flag}
+	switch {
+	case s.Worktree:
+		args = append(args, s.from())
+	case s.To == "":
+		args = append(args, "--cached")
+		if s.From != "" {
+			args = append(args, s.From)
+		}
+	default:
+		args = append(args, s.from(), s.To)
+	}
+	if len(s.Paths) > 0 {
+		args = append(append(args, "--"), s.Paths...)
+	}
+
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,30 @@
+package git
+
+import (
+	"errors"
+	"strings"
+)
+
+// 可以用 errors.Is 判断的错误类别
+var (
+	ErrEmptyDiff      = errors.New("没有可以提交的变更")
+	ErrCommitRejected = errors.New("git commit 失败 (可能被 pre-commit / commit-msg 钩子拒绝)")
+	ErrNotRepository  = errors.New("不在 git 仓库中")
+)
+
+// CommitError 是 git commit 以非零状态退出时返回的错误，Output 包含钩子的输出
+type CommitError struct {
+	Output string
+	Err    error
+}
+
+func (e *CommitError) Error() string {
+	if out := strings.TrimSpace(e.Output); out != "" {
+		return ErrCommitRejected.Error() + ":\n" + out
+	}
+	return ErrCommitRejected.Error() + ": " + e.Err.Error()
+}
+
+func (e *CommitError) Unwrap() []error {
+	return []error{ErrCommitRejected, e.Err}
+}
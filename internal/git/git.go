@@ -7,26 +7,164 @@ import (
 )
 
 func GetStagedDiff() (string, error) {
-	cmd := exec.Command("git", "diff", "--cached", "--diff-algorithm=minimal")
+	return DiffSpec{}.Diff()
+}
+
+func StageAll() error {
+	cmd := exec.Command("git", "add", ".")
+	return cmd.Run()
+}
+
+// GetStagedStat 返回暂存区变更的统计信息 (git diff --cached --stat)
+func GetStagedStat() (string, error) {
+	return DiffSpec{}.Stat()
+}
+
+// TopLevel 返回仓库根目录，不在仓库中时返回空字符串
+func TopLevel() string {
+	output, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// FileAt 读取文件在指定版本中的内容，rev 为空时读取暂存区中的版本
+func FileAt(rev, path string) ([]byte, error) {
+	return exec.Command("git", "show", rev+":"+path).Output()
+}
+
+// ListFiles 列出指定版本中 dir 目录下 (不含子目录) 的文件
+func ListFiles(rev, dir string) ([]string, error) {
+	if dir != "" && dir != "." {
+		dir = strings.TrimSuffix(dir, "/") + "/"
+	} else {
+		dir = ""
+	}
+	output, err := exec.Command("git", "ls-tree", "--name-only", rev, "--", dir).Output()
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(string(output)), nil
+}
+
+// HasHead 判断仓库是否已经有提交
+func HasHead() bool {
+	return exec.Command("git", "rev-parse", "--verify", "-q", "HEAD").Run() == nil
+}
+
+// ParentOf 返回 rev 的第一个父提交，rev 是根提交时返回空 tree，便于作为 diff 的基准
+func ParentOf(rev string) (string, error) {
+	if exec.Command("git", "rev-parse", "--verify", "-q", rev+"^").Run() == nil {
+		return rev + "^", nil
+	}
+	return EmptyTree()
+}
+
+// EmptyTree 返回空 tree 对象的 hash，兼容 SHA-1 和 SHA-256 仓库
+func EmptyTree() (string, error) {
+	cmd := exec.Command("git", "hash-object", "-t", "tree", "--stdin")
+	cmd.Stdin = strings.NewReader("")
 	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
 
+// HeadMessage 返回 HEAD 的完整提交信息
+func HeadMessage() (string, error) {
+	output, err := exec.Command("git", "log", "-1", "--pretty=format:%B").Output()
 	if err != nil {
 		return "", err
 	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// RemoteBranchesContaining 返回包含指定提交的远程分支
+func RemoteBranchesContaining(rev string) []string {
+	output, err := exec.Command("git", "branch", "-r", "--contains", rev, "--format=%(refname:short)").Output()
+	if err != nil {
+		return nil
+	}
+	return splitLines(string(output))
+}
 
+// Upstream 返回当前分支的上游分支 (例如 origin/main)，没有设置时返回空字符串
+func Upstream() string {
+	output, err := exec.Command("git", "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// RemoteDefaultBranch 返回 origin 的默认分支 (例如 origin/main)，未知时返回空字符串
+func RemoteDefaultBranch() string {
+	output, err := exec.Command("git", "symbolic-ref", "--short", "-q", "refs/remotes/origin/HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// MergeBase 返回两个版本的最近公共祖先
+func MergeBase(a, b string) (string, error) {
+	output, err := exec.Command("git", "merge-base", a, b).Output()
+	if err != nil {
+		return "", fmt.Errorf("no common ancestor between %s and %s", a, b)
+	}
 	return strings.TrimSpace(string(output)), nil
 }
 
-func Commit(msg string) {
-	commitCmd := exec.Command("git", "commit", "-m", msg)
-	if out, err := commitCmd.CombinedOutput(); err != nil {
-		fmt.Printf("❌ 提交失败:\n%s\n", string(out))
-	} else {
-		fmt.Println(string(out))
+// LatestTag 返回 rev 可达的最近一个 tag (git describe --tags --abbrev=0)，没有时返回空字符串
+func LatestTag(rev string) string {
+	output, err := exec.Command("git", "describe", "--tags", "--abbrev=0", rev).Output()
+	if err != nil {
+		return ""
 	}
+	return strings.TrimSpace(string(output))
 }
 
-func StageAll() error {
-	cmd := exec.Command("git", "add", ".")
-	return cmd.Run()
+// TagAt 返回指向 rev 的 tag，没有时返回空字符串
+func TagAt(rev string) string {
+	output, err := exec.Command("git", "describe", "--tags", "--exact-match", rev).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// IsTag 判断 name 是否是一个 tag
+func IsTag(name string) bool {
+	return exec.Command("git", "rev-parse", "--verify", "-q", "refs/tags/"+name).Run() == nil
+}
+
+// CommitDate 返回提交的日期 (YYYY-MM-DD)
+func CommitDate(rev string) string {
+	output, err := exec.Command("git", "log", "-1", "--format=%cs", rev).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// MergedTags 返回 rev 可达的所有 tag
+func MergedTags(rev string) ([]string, error) {
+	output, err := exec.Command("git", "tag", "--merged", rev).Output()
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(string(output)), nil
+}
+
+// CreateTag 以 msg 为说明创建附注 tag，保留说明中以 # 开头的行
+func CreateTag(name, msg string) error {
+	cmd := exec.Command("git", "tag", "-a", "--cleanup=verbatim", "-F", "-", name)
+	cmd.Stdin = strings.NewReader(msg)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
 }
@@ -0,0 +1,108 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+)
+
+// LogEntry 描述一条历史提交
+type LogEntry struct {
+	Hash    string
+	Message string   // 完整的提交信息 (标题 + 正文)
+	Files   []string // 该提交修改过的文件
+}
+
+// Subject 返回提交信息的第一行
+func (c LogEntry) Subject() string {
+	subject, _, _ := strings.Cut(c.Message, "\n")
+	return subject
+}
+
+// LogCommits 返回 rev 及之前最近 n 条非合并提交及其修改的文件，由新到旧，rev 为空时表示 HEAD
+func LogCommits(rev string, n int) ([]LogEntry, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+	return readLog(fmt.Sprintf("-n%d", n), "--no-merges", "--name-only", rev, "--")
+}
+
+// RangeCommits 返回 revision range (例如 main..HEAD) 中的提交，由旧到新
+func RangeCommits(revRange string) ([]LogEntry, error) {
+	return readLog("--reverse", "--name-only", revRange)
+}
+
+// readLog 执行 git log 并解析每条提交的 hash、提交信息和修改的文件
+func readLog(args ...string) ([]LogEntry, error) {
+	// 用 \x1e 分隔提交，\x1f 分隔字段，避免和提交信息中的内容冲突
+	args = append([]string{"log", "--pretty=format:%x1e%H%x1f%B%x1f"}, args...)
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []LogEntry
+	for _, record := range strings.Split(string(output), "\x1e") {
+		fields := strings.Split(record, "\x1f")
+		if len(fields) != 3 {
+			continue
+		}
+		commits = append(commits, LogEntry{
+			Hash:    strings.TrimSpace(fields[0]),
+			Message: strings.TrimSpace(fields[1]),
+			Files:   splitLines(fields[2]),
+		})
+	}
+	return commits, nil
+}
+
+// SimilarCommits 按修改路径的重合程度挑选最相似的 n 条提交，不足时用最近的提交补齐
+// 修改了同一文件记 2 分，修改了同一目录记 1 分，同分时较新的提交优先
+func SimilarCommits(commits []LogEntry, paths []string, n int) []LogEntry {
+	files := make(map[string]bool, len(paths))
+	dirs := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		files[p] = true
+		dirs[path.Dir(p)] = true
+	}
+
+	scores := make([]int, len(commits))
+	for i, c := range commits {
+		for _, f := range c.Files {
+			if files[f] {
+				scores[i] += 2
+			} else if dirs[path.Dir(f)] {
+				scores[i]++
+			}
+		}
+	}
+
+	indexes := make([]int, len(commits))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return scores[indexes[a]] > scores[indexes[b]]
+	})
+
+	if n > len(indexes) {
+		n = len(indexes)
+	}
+	result := make([]LogEntry, 0, n)
+	for _, i := range indexes[:n] {
+		result = append(result, commits[i])
+	}
+	return result
+}
+
+func splitLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
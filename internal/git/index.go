@@ -0,0 +1,89 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// WriteTree 将当前暂存区写成 tree 对象并返回其 hash，可用于之后恢复暂存区
+func WriteTree() (string, error) {
+	output, err := exec.Command("git", "write-tree").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ReadTree 将暂存区恢复为指定的 tree，不会修改工作区
+func ReadTree(tree string) error {
+	if out, err := exec.Command("git", "read-tree", tree).CombinedOutput(); err != nil {
+		return fmt.Errorf("git read-tree failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// ResetIndex 将暂存区重置为 HEAD，仓库还没有提交时清空暂存区
+func ResetIndex() error {
+	if !HasHead() {
+		return ReadTree("--empty")
+	}
+	return ReadTree("HEAD")
+}
+
+// ApplyCached 将补丁应用到暂存区 (git apply --cached)，不会修改工作区
+func ApplyCached(patch string) error {
+	cmd := exec.Command("git", "apply", "--cached", "--recount", "-")
+	// 补丁中的路径相对于仓库根目录，在子目录中运行时会被忽略
+	cmd.Dir = TopLevel()
+	cmd.Stdin = strings.NewReader(patch)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git apply --cached failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// StageFromTree 让暂存区中 path 的内容与 tree 中一致，path 不在 tree 中时从暂存区移除
+// path 相对于仓库根目录，命令在根目录中运行，在子目录中同样有效
+func StageFromTree(tree, path string) error {
+	top := TopLevel()
+	lsTree := exec.Command("git", "ls-tree", tree, "--", path)
+	lsTree.Dir = top
+	output, err := lsTree.Output()
+	if err != nil {
+		return err
+	}
+
+	entry := strings.TrimSpace(string(output))
+	var update *exec.Cmd
+	if entry == "" {
+		update = exec.Command("git", "update-index", "--force-remove", "--", path)
+	} else {
+		// 格式: <mode> <type> <hash>\t<path>
+		meta, _, _ := strings.Cut(entry, "\t")
+		fields := strings.Fields(meta)
+		if len(fields) != 3 {
+			return fmt.Errorf("unexpected ls-tree output: %s", entry)
+		}
+		cacheInfo := fmt.Sprintf("%s,%s,%s", fields[0], fields[2], path)
+		update = exec.Command("git", "update-index", "--add", "--cacheinfo", cacheInfo)
+	}
+	update.Dir = top
+	if out, err := update.CombinedOutput(); err != nil {
+		return fmt.Errorf("git update-index failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// HasStagedChanges 判断暂存区相对于 HEAD 是否有变更
+func HasStagedChanges() bool {
+	return Repo{}.HasStagedChanges()
+}
+
+// ResetSoft 将 HEAD 移动到 rev，保留暂存区和工作区 (git reset --soft)
+func ResetSoft(rev string) error {
+	if out, err := exec.Command("git", "reset", "--soft", rev).CombinedOutput(); err != nil {
+		return fmt.Errorf("git reset --soft failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
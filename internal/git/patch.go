@@ -0,0 +1,77 @@
+package git
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// ErrNoContent 表示补丁中只有 diff，无法读取文件的完整内容
+var ErrNoContent = errors.New("补丁中不包含文件的完整内容")
+
+// Patch 是来自补丁文件或标准输入的 diff，不对应仓库中的任何版本，
+// 用于为之后通过 git am 应用或通过邮件发送的补丁起草提交信息
+type Patch struct {
+	Text string
+}
+
+// Diff 返回补丁中的 diff 文本
+func (p Patch) Diff() (string, error) {
+	return strings.TrimSpace(p.Text), nil
+}
+
+// Stat 返回补丁的统计信息 (git apply --stat)
+func (p Patch) Stat() (string, error) {
+	return p.apply("--stat")
+}
+
+// Files 返回补丁修改的文件路径，重命名时返回新路径
+func (p Patch) Files() ([]string, error) {
+	output, err := p.apply("--numstat", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	// 格式: <added>\t<deleted>\t<path>\0，重命名时 path 为空，之后依次是旧路径和新路径
+	var files []string
+	fields := strings.Split(output, "\x00")
+	for i := 0; i < len(fields); i++ {
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[2] != "" {
+			files = append(files, parts[2])
+		} else if i+2 < len(fields) {
+			files = append(files, fields[i+2])
+			i += 2
+		}
+	}
+	return files, nil
+}
+
+// Before 总是返回 ErrNoContent
+func (p Patch) Before(path string) ([]byte, error) {
+	return nil, ErrNoContent
+}
+
+// After 总是返回 ErrNoContent
+func (p Patch) After(path string) ([]byte, error) {
+	return nil, ErrNoContent
+}
+
+// ListBefore 总是返回 ErrNoContent
+func (p Patch) ListBefore(dir string) ([]string, error) {
+	return nil, ErrNoContent
+}
+
+// apply 使用 git apply 分析补丁，不会修改暂存区或工作区，在仓库之外同样可用
+func (p Patch) apply(args ...string) (string, error) {
+	cmd := exec.Command("git", append(append([]string{"apply"}, args...), "-")...)
+	cmd.Stdin = strings.NewReader(p.Text)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,188 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Repo 描述一个 git 仓库及当前所在的工作区。
+// 它的方法在 TopLevel 中执行 git，不依赖进程的当前目录，可以同时处理多个仓库；
+// 零值表示当前目录所在的仓库
+type Repo struct {
+	TopLevel     string // 工作区根目录
+	GitDir       string // 当前工作区的 git 目录，linked worktree 中为 <CommonDir>/worktrees/<name>
+	CommonDir    string // 所有工作区共享的 git 目录
+	Superproject string // 作为子模块时所在的上级仓库根目录，否则为空
+}
+
+// Name 返回仓库根目录的名称
+func (r Repo) Name() string {
+	return filepath.Base(r.TopLevel)
+}
+
+// IsLinkedWorktree 表示当前工作区是通过 git worktree add 创建的附加工作区
+func (r Repo) IsLinkedWorktree() bool {
+	return r.GitDir != r.CommonDir
+}
+
+// IsSubmodule 表示仓库是另一个仓库的子模块
+func (r Repo) IsSubmodule() bool {
+	return r.Superproject != ""
+}
+
+// Discover 查找 dir 所在的仓库，dir 为空时使用当前目录。
+// 不在仓库中或位于没有工作区的裸仓库中时返回 ErrNotRepository
+func Discover(dir string) (Repo, error) {
+	args := []string{"rev-parse", "--path-format=absolute",
+		"--show-toplevel", "--absolute-git-dir", "--git-common-dir", "--show-superproject-working-tree"}
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		if dir == "" {
+			dir = "."
+		}
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		return Repo{}, fmt.Errorf("%w: %s", ErrNotRepository, dir)
+	}
+
+	// 裸仓库中没有 --show-toplevel 的输出
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+	if len(lines) < 3 {
+		return Repo{}, fmt.Errorf("%w: %s", ErrNotRepository, dir)
+	}
+	repo := Repo{
+		TopLevel:  lines[0],
+		GitDir:    lines[1],
+		CommonDir: filepath.Clean(lines[2]),
+	}
+	if len(lines) > 3 {
+		repo.Superproject = lines[3]
+	}
+	return repo, nil
+}
+
+// gitPath 返回当前工作区 git 目录中的文件，例如 MERGE_HEAD、rebase-merge 和 AICOMMITS_MSG，
+// 这些文件在 linked worktree 中位于各自的 GitDir 而不是 CommonDir
+func (r Repo) gitPath(name string) string {
+	if r.GitDir != "" {
+		return filepath.Join(r.GitDir, name)
+	}
+	output, err := r.command("rev-parse", "--path-format=absolute", "--git-path", name).Output()
+	if err != nil {
+		return name
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// MessageFileName 是保存最近一次提交信息的文件名，位于当前工作区的 git 目录中
+const MessageFileName = "AICOMMITS_MSG"
+
+// SaveMessage 把提交信息保存到 AICOMMITS_MSG 并返回文件路径，提交失败或取消时可以用 git commit -F 恢复
+func (r Repo) SaveMessage(msg string) (string, error) {
+	path := r.gitPath(MessageFileName)
+	return path, os.WriteFile(path, []byte(strings.TrimSpace(msg)+"\n"), 0o644)
+}
+
+// SubmoduleLog 返回子模块 path (相对于仓库根目录) 中 from..to 之间的提交 ("<hash> <subject>")，
+// 由新到旧，最多 n 个
+func (r Repo) SubmoduleLog(path, from, to string, n int) ([]string, error) {
+	top := r.TopLevel
+	if top == "" {
+		top = TopLevel()
+	}
+	dir := filepath.Join(top, path)
+	// 未检出的子模块是空目录，git -C 会落到上级仓库中
+	if sub, err := Discover(dir); err != nil || sub.TopLevel != dir {
+		return nil, fmt.Errorf("submodule %s is not checked out", path)
+	}
+	output, err := exec.Command("git", "-C", dir, "log", "--format=%h %s", fmt.Sprintf("-n%d", n), from+".."+to).Output()
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(string(output)), nil
+}
+
+// command 返回在仓库根目录中执行的 git 命令
+func (r Repo) command(args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.TopLevel
+	return cmd
+}
+
+// StagedDiff 返回暂存区相对于 HEAD 的 diff
+func (r Repo) StagedDiff() (string, error) {
+	output, err := r.command("diff", "--cached", "--diff-algorithm=minimal").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// StagedStat 返回暂存区变更的统计信息 (git diff --cached --stat)
+func (r Repo) StagedStat() (string, error) {
+	output, err := r.command("diff", "--cached", "--stat").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// HasStagedChanges 判断暂存区相对于 HEAD 是否有变更
+func (r Repo) HasStagedChanges() bool {
+	return r.command("diff", "--cached", "--quiet").Run() != nil
+}
+
+// Branch 返回当前分支名，游离 HEAD 时返回空字符串
+func (r Repo) Branch() string {
+	output, err := r.command("symbolic-ref", "--short", "-q", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// RecentCommits 返回最近 n 条提交的标题，由新到旧
+func (r Repo) RecentCommits(n int) []string {
+	output, err := r.command("log", fmt.Sprintf("-n%d", n), "--pretty=format:%s").Output()
+	if err != nil {
+		return nil
+	}
+	text := strings.TrimSpace(string(output))
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// Commit 使用 msg 提交暂存区，args 是额外的 git commit 参数 (例如 --amend)，返回 git commit 的输出
+// 提交信息通过临时文件 (-F) 传递，避免过长的信息或以 - 开头的信息被当作参数
+func (r Repo) Commit(msg string, args ...string) (string, error) {
+	file, err := os.CreateTemp("", "aicommits-msg-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(msg); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	// -F 放在前面，args 中可能以 -- <pathspec> 结尾
+	cmdArgs := append([]string{"commit", "-F", file.Name()}, args...)
+	out, err := r.command(cmdArgs...).CombinedOutput()
+	if err != nil {
+		return string(out), &CommitError{Output: string(out), Err: err}
+	}
+	return string(out), nil
+}
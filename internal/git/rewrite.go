@@ -0,0 +1,103 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// HasMerges 判断 revision range 中是否包含合并提交
+func HasMerges(revRange string) bool {
+	output, err := exec.Command("git", "rev-list", "--merges", revRange).Output()
+	return err == nil && strings.TrimSpace(string(output)) != ""
+}
+
+// IsAncestor 判断 rev 是否是 HEAD 的祖先 (或就是 HEAD)
+func IsAncestor(rev string) bool {
+	return exec.Command("git", "merge-base", "--is-ancestor", rev, "HEAD").Run() == nil
+}
+
+// RewordCommits 通过非交互式的 git rebase 修改 HEAD 祖先中若干提交的信息
+// messages 的 key 是完整的提交 hash；作者信息和作者时间会被保留，rebase 失败时自动 abort
+func RewordCommits(messages map[string]string) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	// 找到最早需要修改的提交，从它的父提交开始 rebase
+	output, err := exec.Command("git", "rev-list", "--reverse", "--topo-order", "HEAD").Output()
+	if err != nil {
+		return err
+	}
+	first := ""
+	for _, hash := range splitLines(string(output)) {
+		if _, ok := messages[hash]; ok {
+			first = hash
+			break
+		}
+	}
+	if first == "" {
+		return fmt.Errorf("commits to reword are not reachable from HEAD")
+	}
+
+	rebaseArgs := []string{"rebase", "-i", "--autostash"}
+	revRange := "HEAD"
+	if parent, err := exec.Command("git", "rev-parse", "--verify", "-q", first+"^").Output(); err == nil {
+		base := strings.TrimSpace(string(parent))
+		rebaseArgs = append(rebaseArgs, base)
+		revRange = base + "..HEAD"
+	} else {
+		rebaseArgs = append(rebaseArgs, "--root")
+	}
+	if HasMerges(revRange) {
+		return fmt.Errorf("the range to rebase contains merge commits")
+	}
+
+	output, err = exec.Command("git", "rev-list", "--reverse", revRange).Output()
+	if err != nil {
+		return err
+	}
+	picks := splitLines(string(output))
+
+	dir, err := os.MkdirTemp("", "aicommits-reword-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// 预先写好完整的 todo，需要修改的提交后面跟一条 exec 执行 amend
+	var todo strings.Builder
+	for i, hash := range picks {
+		fmt.Fprintf(&todo, "pick %s\n", hash)
+		msg, ok := messages[hash]
+		if !ok {
+			continue
+		}
+		msgFile := filepath.Join(dir, fmt.Sprintf("msg-%d", i))
+		if err := os.WriteFile(msgFile, []byte(msg+"\n"), 0o600); err != nil {
+			return err
+		}
+		fmt.Fprintf(&todo, "exec git commit --amend --allow-empty --no-verify --cleanup=strip -F %s\n", shellQuote(msgFile))
+	}
+	todoFile := filepath.Join(dir, "todo")
+	if err := os.WriteFile(todoFile, []byte(todo.String()), 0o600); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", rebaseArgs...)
+	cmd.Env = append(os.Environ(),
+		"GIT_SEQUENCE_EDITOR=cp "+shellQuote(todoFile),
+		"GIT_EDITOR=true",
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		exec.Command("git", "rebase", "--abort").Run()
+		return fmt.Errorf("git rebase failed and was aborted:\n%s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,124 @@
+package git
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// workspaceRoots 是 monorepo 中常见的工作区目录，其下一级目录名会作为 scope
+var workspaceRoots = []string{"packages", "apps", "services", "libs", "modules", "plugins"}
+
+// InferScope 根据变更文件路径推断提交的 scope
+// 每个文件依次尝试: 配置的路径→scope 映射表 (最长前缀优先)、monorepo 工作区目录、Go 包名
+// 只有所有能推断出 scope 的文件结果一致时才返回该 scope，否则返回空字符串
+func InferScope(paths []string, table map[string]string) string {
+	roots := append(goWorkspaceDirs(), workspaceRoots...)
+
+	scope := ""
+	for _, p := range paths {
+		candidate := scopeFromTable(p, table)
+		if candidate == "" {
+			candidate = scopeFromWorkspace(p, roots)
+		}
+		if candidate == "" && strings.HasSuffix(p, ".go") {
+			candidate = stagedGoPackage(p)
+		}
+
+		if candidate == "" {
+			continue
+		}
+		if scope != "" && scope != candidate {
+			return ""
+		}
+		scope = candidate
+	}
+	return scope
+}
+
+func scopeFromTable(p string, table map[string]string) string {
+	best, scope := -1, ""
+	for prefix, s := range table {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if p != prefix && !strings.HasPrefix(p, prefix+"/") {
+			continue
+		}
+		if len(prefix) > best {
+			best, scope = len(prefix), s
+		}
+	}
+	return scope
+}
+
+func scopeFromWorkspace(p string, roots []string) string {
+	for _, root := range roots {
+		rest, ok := strings.CutPrefix(p, root+"/")
+		if !ok {
+			continue
+		}
+		if dir, _, ok := strings.Cut(rest, "/"); ok {
+			return dir
+		}
+	}
+	return ""
+}
+
+// goWorkspaceDirs 读取仓库根目录下 go.work 中 use 的模块目录的上级目录
+// 例如 use ./svc/api 时，svc/api 下的文件会以 api 作为 scope
+func goWorkspaceDirs() []string {
+	content, err := os.ReadFile(filepath.Join(TopLevel(), "go.work"))
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	inBlock := false
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "use ("):
+			inBlock = true
+			continue
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case strings.HasPrefix(line, "use "):
+			line = strings.TrimPrefix(line, "use ")
+		case !inBlock:
+			continue
+		}
+
+		dir := path.Clean(strings.Trim(line, `"`))
+		if dir == "." || dir == "" {
+			continue
+		}
+		if parent := path.Dir(dir); parent != "." {
+			dirs = append(dirs, parent)
+		}
+	}
+	return dirs
+}
+
+// stagedGoPackage 读取暂存区中 Go 文件的包名，测试包的 _test 后缀会被去掉
+func stagedGoPackage(p string) string {
+	content, err := FileAt("", p)
+	if err != nil {
+		// 文件被删除时读取 HEAD 中的版本
+		if content, err = FileAt("HEAD", p); err != nil {
+			return ""
+		}
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), p, content, parser.PackageClauseOnly)
+	if err != nil {
+		return ""
+	}
+	name := strings.TrimSuffix(file.Name.Name, "_test")
+	if name == "main" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,117 @@
+package git
+
+import (
+	"os"
+	"strings"
+)
+
+// Operation 是仓库中正在进行、等待提交的多步操作
+type Operation string
+
+const (
+	OpNone       Operation = ""
+	OpMerge      Operation = "merge"
+	OpCherryPick Operation = "cherry-pick"
+	OpRevert     Operation = "revert"
+	OpRebase     Operation = "rebase"
+)
+
+// RepoState 描述正在进行的操作，以及 git commit 在没有 -m / -F 时会使用的信息
+type RepoState struct {
+	Operation Operation
+	Heads     []string // 被合并、挑选、撤销或正在变基的提交，合并多个分支时有多个
+	Message   string   // git 准备好的提交信息 (MERGE_MSG)，变基时为正在应用的提交的信息，已去掉注释行
+	Reference string   // 需要保留的引用行，例如 "This reverts commit <hash>."
+	Conflicts []string // 解决过冲突的文件，读取自 MERGE_MSG 中的 "# Conflicts:" 注释
+}
+
+// stateHeads 是各操作对应的伪引用，按检查顺序排列
+var stateHeads = []struct {
+	op  Operation
+	ref string
+}{
+	{OpMerge, "MERGE_HEAD"},
+	{OpCherryPick, "CHERRY_PICK_HEAD"},
+	{OpRevert, "REVERT_HEAD"},
+}
+
+// State 返回正在进行的合并、cherry-pick、revert 或变基，没有时 Operation 为 OpNone
+func (r Repo) State() RepoState {
+	for _, s := range stateHeads {
+		data, err := os.ReadFile(r.gitPath(s.ref))
+		if err != nil {
+			continue
+		}
+		state := RepoState{Operation: s.op, Heads: strings.Fields(string(data))}
+		if raw, err := os.ReadFile(r.gitPath("MERGE_MSG")); err == nil {
+			state.Message, state.Conflicts = parsePreparedMessage(string(raw))
+		}
+		state.Reference = referenceLine(state)
+		return state
+	}
+
+	if isDir(r.gitPath("rebase-merge")) || isDir(r.gitPath("rebase-apply")) {
+		state := RepoState{Operation: OpRebase}
+		// REBASE_HEAD 是停下来时正在应用的提交，刚开始或在 exec 步骤时可能不存在
+		if head, err := r.command("rev-parse", "--verify", "-q", "REBASE_HEAD").Output(); err == nil {
+			state.Heads = []string{strings.TrimSpace(string(head))}
+			if msg, err := r.command("log", "-1", "--format=%B", "REBASE_HEAD").Output(); err == nil {
+				state.Message = strings.TrimSpace(string(msg))
+			}
+		}
+		return state
+	}
+	return RepoState{}
+}
+
+// UnmergedFiles 返回还有未解决冲突的文件，路径相对于仓库根目录
+func (r Repo) UnmergedFiles() []string {
+	output, err := r.command("diff", "--name-only", "--diff-filter=U").Output()
+	if err != nil {
+		return nil
+	}
+	return splitLines(string(output))
+}
+
+// parsePreparedMessage 去掉 MERGE_MSG 中的注释行，并读取其中列出的冲突文件:
+//
+//	# Conflicts:
+//	#	path/to/file
+func parsePreparedMessage(raw string) (msg string, conflicts []string) {
+	var lines []string
+	inConflicts := false
+	for _, line := range strings.Split(raw, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			inConflicts = false
+			lines = append(lines, line)
+			continue
+		}
+		comment := strings.TrimSpace(strings.TrimPrefix(line, "#"))
+		switch {
+		case comment == "Conflicts:":
+			inConflicts = true
+		case inConflicts && comment != "":
+			conflicts = append(conflicts, comment)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), conflicts
+}
+
+// referenceLine 返回 revert 和 cherry-pick -x 的提交信息中指向原提交的一行
+func referenceLine(state RepoState) string {
+	for _, line := range strings.Split(state.Message, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "This reverts commit ") || strings.HasPrefix(line, "(cherry picked from commit ") {
+			return line
+		}
+	}
+	if state.Operation == OpRevert && len(state.Heads) > 0 {
+		return "This reverts commit " + state.Heads[0] + "."
+	}
+	return ""
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
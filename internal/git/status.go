@@ -0,0 +1,140 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// FileStatus 是 git status 中的一个文件及其暂存区、工作区的变更行数
+type FileStatus struct {
+	Path     string
+	OrigPath string // 重命名或复制前的路径，没有时为空
+	Staged   byte   // 暂存区状态 (porcelain 的 X)，' ' 表示没有暂存的变更，'?' 表示未跟踪
+	Unstaged byte   // 工作区状态 (porcelain 的 Y)
+
+	StagedAdded, StagedDeleted     int
+	UnstagedAdded, UnstagedDeleted int
+}
+
+// IsUntracked 表示文件未被跟踪
+func (f FileStatus) IsUntracked() bool {
+	return f.Staged == '?'
+}
+
+// HasStaged 表示文件有已暂存的变更
+func (f FileStatus) HasStaged() bool {
+	return f.Staged != ' ' && f.Staged != '?'
+}
+
+// HasUnstaged 表示文件有未暂存的变更 (包括未跟踪)
+func (f FileStatus) HasUnstaged() bool {
+	return f.Unstaged != ' '
+}
+
+// Status 返回所有已修改、已暂存和未跟踪的文件，未跟踪的目录会展开为文件。
+// 路径都相对于仓库根目录，可以直接传给 StagePaths、UnstagePaths 和 WorktreeDiff
+func Status() ([]FileStatus, error) {
+	output, err := exec.Command("git", "status", "--porcelain=v1", "-z", "--untracked-files=all").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []FileStatus
+	fields := strings.Split(string(output), "\x00")
+	for i := 0; i < len(fields); i++ {
+		entry := fields[i]
+		if len(entry) < 4 {
+			continue
+		}
+		f := FileStatus{Staged: entry[0], Unstaged: entry[1], Path: entry[3:]}
+		// 重命名和复制的原路径在下一个字段
+		if f.Staged == 'R' || f.Staged == 'C' {
+			i++
+			if i < len(fields) {
+				f.OrigPath = fields[i]
+			}
+		}
+		files = append(files, f)
+	}
+
+	staged, _ := numstat("--cached")
+	unstaged, _ := numstat()
+	for i, f := range files {
+		files[i].StagedAdded, files[i].StagedDeleted = staged[f.Path][0], staged[f.Path][1]
+		files[i].UnstagedAdded, files[i].UnstagedDeleted = unstaged[f.Path][0], unstaged[f.Path][1]
+	}
+	return files, nil
+}
+
+// numstat 返回每个文件新增和删除的行数，二进制文件为 0
+func numstat(args ...string) (map[string][2]int, error) {
+	cmdArgs := append([]string{"diff", "--numstat", "-z", "--no-renames"}, args...)
+	output, err := exec.Command("git", cmdArgs...).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string][2]int)
+	for _, entry := range strings.Split(string(output), "\x00") {
+		parts := strings.SplitN(entry, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		added, _ := strconv.Atoi(parts[0])
+		deleted, _ := strconv.Atoi(parts[1])
+		stats[parts[2]] = [2]int{added, deleted}
+	}
+	return stats, nil
+}
+
+// topPaths 将相对于仓库根目录的路径转换为 pathspec，在子目录中运行时同样有效
+func topPaths(paths []string) []string {
+	specs := make([]string, len(paths))
+	for i, p := range paths {
+		specs[i] = ":(top,literal)" + p
+	}
+	return specs
+}
+
+// StagePaths 暂存指定路径 (相对于仓库根目录) 的全部变更，包括删除 (git add -A)
+func StagePaths(paths ...string) error {
+	args := append([]string{"add", "-A", "--"}, topPaths(paths)...)
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("git add failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// UnstagePaths 取消暂存指定路径 (相对于仓库根目录)，工作区不受影响
+func UnstagePaths(paths ...string) error {
+	args := append([]string{"reset", "-q", "--"}, topPaths(paths)...)
+	if !HasHead() {
+		args = append([]string{"rm", "--cached", "-r", "-q", "--"}, topPaths(paths)...)
+	}
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// WorktreeDiff 返回工作区相对于暂存区的 diff，paths (相对于仓库根目录) 为空时包含所有文件
+func WorktreeDiff(paths ...string) (string, error) {
+	args := append([]string{"diff", "--no-color", "--no-ext-diff", "--"}, topPaths(paths)...)
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// CheckPathspecs 确认每个 pathspec 都匹配已跟踪的文件，与 git commit -- <paths> 的要求一致
+func CheckPathspecs(paths []string) error {
+	for _, p := range paths {
+		if err := exec.Command("git", "ls-files", "--error-unmatch", "--", p).Run(); err != nil {
+			return fmt.Errorf("pathspec '%s' 没有匹配任何已跟踪的文件", p)
+		}
+	}
+	return nil
+}
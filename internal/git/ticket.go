@@ -0,0 +1,32 @@
+package git
+
+import "regexp"
+
+// DefaultTicketPattern 匹配 PROJ-1234 形式的工单编号
+const DefaultTicketPattern = `[A-Z][A-Z0-9]+-\d+`
+
+// TicketIDs 用 pattern 从分支名中提取工单编号，按出现顺序去重
+// pattern 中有捕获组时取第一个捕获组，否则取整个匹配
+func TicketIDs(branch, pattern string) ([]string, error) {
+	if pattern == "" {
+		pattern = DefaultTicketPattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, m := range re.FindAllStringSubmatch(branch, -1) {
+		id := m[0]
+		if len(m) > 1 {
+			id = m[1]
+		}
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
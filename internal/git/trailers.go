@@ -0,0 +1,65 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// InterpretTrailers 按 git interpret-trailers 的规则把 trailers 加入提交信息
+// 与已有 trailer 完全相同的行不会重复添加 (--if-exists addIfDifferent)
+func InterpretTrailers(msg string, trailers []string) (string, error) {
+	if len(trailers) == 0 {
+		return msg, nil
+	}
+
+	args := []string{"interpret-trailers", "--if-exists", "addIfDifferent"}
+	for _, t := range trailers {
+		args = append(args, "--trailer", t)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = strings.NewReader(strings.TrimSpace(msg) + "\n")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git interpret-trailers 失败: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Identity 返回当前用户的 "Name <email>"，用于 Signed-off-by
+func Identity() (string, error) {
+	output, err := exec.Command("git", "var", "GIT_COMMITTER_IDENT").Output()
+	if err != nil {
+		return "", fmt.Errorf("无法读取 git 用户信息: %w", err)
+	}
+	// 输出格式为 "Name <email> timestamp timezone"
+	ident := strings.TrimSpace(string(output))
+	if i := strings.LastIndex(ident, ">"); i >= 0 {
+		ident = ident[:i+1]
+	}
+	return ident, nil
+}
+
+// CoAuthorCandidates 从最近 n 个提交中统计作者 (按 .mailmap 归一)，按提交数量降序返回，不包括当前用户
+func CoAuthorCandidates(n int) []string {
+	output, err := exec.Command("git", "log", fmt.Sprintf("-n%d", n), "--format=%aN <%aE>").Output()
+	if err != nil {
+		return nil
+	}
+
+	self, _ := Identity()
+	counts := make(map[string]int)
+	var authors []string
+	for _, author := range splitLines(string(output)) {
+		if strings.EqualFold(author, self) {
+			continue
+		}
+		if counts[author] == 0 {
+			authors = append(authors, author)
+		}
+		counts[author]++
+	}
+	sort.SliceStable(authors, func(i, j int) bool { return counts[authors[i]] > counts[authors[j]] })
+	return authors
+}
@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+)
+
+// ChangelogOptions 定义润色变更日志所需的参数，同时也是变更日志模板中可用的变量
+type ChangelogOptions struct {
+	Language string // "cn" 或 "en"
+	Notes    string // 按分类整理好的变更日志小节 (Markdown)
+
+	// TemplateDirs 按优先级排列的模板查找目录
+	TemplateDirs []string
+}
+
+const (
+	// ChangelogSystemTemplateName 和 ChangelogUserTemplateName 是可覆盖的变更日志模板文件名
+	ChangelogSystemTemplateName = "changelog.system.tmpl"
+	ChangelogUserTemplateName   = "changelog.user.tmpl"
+)
+
+const (
+	defaultChangelogSystemTpl = `
+<role>
+You are a technical writer who turns commit-based changelogs into readable release notes.
+</role>
+<restriction>
+- Keep the version heading and the "### <category>" headings exactly as they are, and keep their order.
+- Rewrite every entry as a clear sentence for users of the project; merge entries that describe the same change.
+- Keep the bold scope prefix and the commit hash in parentheses when an entry keeps them.
+- Do NOT add new categories or invent changes that are not listed.
+- Return only the Markdown, without code fences.
+{{- if eq .Language "cn"}}
+- The release notes **MUST** be written in Simplified Chinese (简体中文), but keep the headings in English.
+{{- else}}
+- The release notes **MUST** be written in English.
+{{- end}}
+</restriction>
+`
+
+	defaultChangelogUserTpl = `Here are the grouped changelog entries:
+
+{{.Notes}}`
+)
+
+// PolishChangelog 请求模型将分组后的变更日志润色为可读的发布说明
+func PolishChangelog(ctx context.Context, client Client, opts ChangelogOptions) (string, error) {
+	messages, err := renderMessages(opts.TemplateDirs, "", opts,
+		ChangelogSystemTemplateName, defaultChangelogSystemTpl, ChangelogUserTemplateName, defaultChangelogUserTpl)
+	if err != nil {
+		return "", err
+	}
+
+	reply, err := client.Chat(ctx, messages)
+	if err != nil {
+		return "", err
+	}
+
+	notes := stripCodeFence(reply)
+	if notes == "" {
+		return "", fmt.Errorf("empty release notes from model")
+	}
+	return notes, nil
+}
@@ -1,11 +1,51 @@
 package llm
 
-import "context"
+import (
+	"context"
+	"strings"
+)
 
 // Client 定义了所有 LLM 提供商必须实现的通用接口
 // 无论是 OpenAI, DeepSeek 还是 Ollama，都必须满足这个契约
 type Client interface {
-	GenerateCommitMessage(ctx context.Context, diff string) (string, error)
+	GenerateCommitMessage(ctx context.Context, opts PromptOptions) (string, error)
+	// Chat 发送任意消息并返回模型的回复，用于提交信息之外的生成任务
+	Chat(ctx context.Context, messages []Message) (string, error)
+}
+
+// postProcessClient 在生成结果上依次执行后处理函数
+type postProcessClient struct {
+	Client
+	fns []func(string) string
+}
+
+// WithPostProcess 返回一个对生成结果做确定性补充的 Client，例如在正文末尾追加内容
+func WithPostProcess(client Client, fns ...func(string) string) Client {
+	if len(fns) == 0 {
+		return client
+	}
+	return &postProcessClient{Client: client, fns: fns}
+}
+
+func (c *postProcessClient) GenerateCommitMessage(ctx context.Context, opts PromptOptions) (string, error) {
+	msg, err := c.Client.GenerateCommitMessage(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+	for _, fn := range c.fns {
+		msg = fn(msg)
+	}
+	return msg, nil
+}
+
+// AppendBody 返回一个在提交信息末尾追加一段正文的后处理函数
+func AppendBody(body string) func(string) string {
+	return func(msg string) string {
+		if body == "" {
+			return msg
+		}
+		return strings.TrimRight(msg, "\n") + "\n\n" + body
+	}
 }
 
 type Message struct {
@@ -0,0 +1,68 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// 可以用 errors.Is 判断的错误类别
+var (
+	ErrAuth      = errors.New("authentication failed")
+	ErrRateLimit = errors.New("rate limited")
+	ErrNetwork   = errors.New("network error")
+)
+
+// APIError 是模型服务返回的非 200 响应
+type APIError struct {
+	StatusCode int
+	Message    string // 从响应中解析出的错误信息，无法解析时为空
+	Body       string // 原始响应内容
+}
+
+// newAPIError 从响应中解析常见的错误格式:
+// {"error": {"message": "..."}}、{"error": "..."} 和 {"message": "..."}
+func newAPIError(status int, body []byte) *APIError {
+	e := &APIError{StatusCode: status, Body: strings.TrimSpace(string(body))}
+
+	var payload struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+	}
+	if json.Unmarshal(body, &payload) != nil {
+		return e
+	}
+	var nested struct {
+		Message string `json:"message"`
+	}
+	var plain string
+	switch {
+	case json.Unmarshal(payload.Error, &nested) == nil && nested.Message != "":
+		e.Message = nested.Message
+	case json.Unmarshal(payload.Error, &plain) == nil && plain != "":
+		e.Message = plain
+	default:
+		e.Message = payload.Message
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// Is 将 401/403 归为 ErrAuth，429 归为 ErrRateLimit
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrAuth:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimit:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
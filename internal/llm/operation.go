@@ -0,0 +1,31 @@
+package llm
+
+import "strings"
+
+// 正在进行的 git 操作，与 git.Operation 的取值一致
+const (
+	OperationMerge      = "merge"
+	OperationCherryPick = "cherry-pick"
+	OperationRevert     = "revert"
+	OperationRebase     = "rebase"
+)
+
+// ApplyOperation 返回一个后处理函数，保留 git 准备的信息中指向原提交的引用行
+// (例如 "This reverts commit <hash>.")，并在正文末尾列出解决过冲突的文件
+func ApplyOperation(reference string, conflicts []string) func(string) string {
+	return func(msg string) string {
+		if reference != "" && !strings.Contains(msg, reference) {
+			msg = AppendBody(reference)(msg)
+		}
+		if len(conflicts) > 0 && !strings.Contains(msg, "Resolved conflicts:") {
+			msg = AppendBody("Resolved conflicts:\n- " + strings.Join(conflicts, "\n- "))(msg)
+		}
+		return msg
+	}
+}
+
+// firstLine 返回文本的第一行，用于在模板中引用 git 准备的提交信息标题
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return line
+}
@@ -0,0 +1,119 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PROptions 定义生成 Pull Request 标题和描述所需的参数，同时也是 PR 模板中可用的变量
+// 除了 PromptOptions 中的字段 (例如 {{.Diff}}、{{.Branch}})，还可以使用 {{.Base}} 和 {{.Commits}}
+type PROptions struct {
+	PromptOptions
+	Base    string   // PR 的目标分支
+	Commits []string // PR 中各提交的完整信息，由旧到新
+}
+
+const (
+	// PRSystemTemplateName 和 PRUserTemplateName 是可覆盖的 PR 模板文件名
+	PRSystemTemplateName = "pr.system.tmpl"
+	PRUserTemplateName   = "pr.user.tmpl"
+)
+
+const (
+	defaultPRSystemTpl = `
+<role>
+You are an expert developer who writes clear pull request descriptions for code review.
+</role>
+<goal>
+Write a pull request title and description for merging branch "{{.Branch}}" into "{{.Base}}".
+</goal>
+<restriction>
+- The first line is the title, less than 72 characters, without any prefix like "Title:" or "#".
+{{- if or (eq .Convention "") (eq .Convention "conventional")}}
+- The title uses the Conventional Commits format: <type>[optional scope]: <subject>
+{{- end}}
+- Leave a blank line after the title, then write the Markdown body with exactly these sections:
+## Summary
+## Changes
+## Test notes
+## Breaking changes
+- "Summary" is 1 - 3 sentences about what the PR does and why.
+- "Changes" is a bulleted list of the notable changes.
+- "Test notes" explains how the changes can be verified, based on the tests in the diff if any.
+- "Breaking changes" lists incompatible changes, or just "None".
+- Do NOT wrap the whole output in code fences.
+{{- if eq .Language "cn"}}
+- The title and description **MUST** be written in Simplified Chinese (简体中文).
+{{- else}}
+- The title and description **MUST** be written in English.
+{{- end}}
+</restriction>
+`
+
+	defaultPRUserTpl = `Here are the commits in this pull request, from oldest to newest:
+{{range .Commits}}
+---
+{{.}}
+{{- end}}
+
+{{if .Summary -}}
+Here is a semantic summary of the Go API changes:
+
+{{.Summary}}
+
+{{end -}}
+{{if .Dependencies -}}
+Here are the dependency changes parsed from the manifests:
+
+{{.Dependencies}}
+
+{{end -}}
+Here is the git diff output:
+
+{{.Diff}}`
+)
+
+// ConstructPRMessages 渲染 PR 模板并返回发送给模型的消息
+func ConstructPRMessages(opts PROptions) ([]Message, error) {
+	return renderMessages(opts.TemplateDirs, "", opts,
+		PRSystemTemplateName, defaultPRSystemTpl, PRUserTemplateName, defaultPRUserTpl)
+}
+
+// GeneratePR 请求模型生成 PR 的标题和 Markdown 描述
+func GeneratePR(ctx context.Context, client Client, opts PROptions) (string, string, error) {
+	messages, err := ConstructPRMessages(opts)
+	if err != nil {
+		return "", "", err
+	}
+
+	reply, err := client.Chat(ctx, messages)
+	if err != nil {
+		return "", "", err
+	}
+
+	title, body := splitTitle(stripCodeFence(reply))
+	if title == "" {
+		return "", "", fmt.Errorf("empty pull request from model")
+	}
+	return title, body, nil
+}
+
+// splitTitle 将第一行非空内容作为标题，其余部分作为正文
+func splitTitle(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	title, body, _ := strings.Cut(text, "\n")
+	title = strings.TrimSpace(strings.TrimLeft(title, "# "))
+	title = strings.TrimPrefix(title, "Title:")
+	return strings.TrimSpace(title), strings.TrimSpace(body)
+}
+
+// stripCodeFence 去掉模型有时会加上的整体代码块标记
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	_, text, _ = strings.Cut(text, "\n")
+	return strings.TrimSuffix(strings.TrimSpace(text), "```")
+}
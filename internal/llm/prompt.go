@@ -1,63 +1,302 @@
 package llm
 
-import "fmt"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// CommitType 描述一种提交类型及其适用场景
+type CommitType struct {
+	Name        string
+	Description string
+}
+
+// DefaultTypes 是默认提供给模型的提交类型定义
+var DefaultTypes = []CommitType{
+	{Name: "feat", Description: "Used for adding new features or user-facing capabilities."},
+	{Name: "fix", Description: "Used for fixing bugs or incorrect behavior."},
+	{Name: "build", Description: "Used for modifying the project build system, such as changing dependencies, external interfaces, or upgrading Node versions."},
+	{Name: "chore", Description: "Used for modifying non-business code, such as changing build processes or tool configurations."},
+	{Name: "ci", Description: "Used for modifying the Continuous Integration process, such as changing Travis, Jenkins, or other workflow configurations."},
+	{Name: "docs", Description: "Used for modifying documentation, such as changing README files or API documentation."},
+	{Name: "style", Description: "Used for modifying code style, such as adjusting indentation, spaces, blank lines, etc."},
+	{Name: "refactor", Description: "Used for code refactoring, such as modifying code structure, variable names, or function names without changing functional logic."},
+	{Name: "perf", Description: "Used for performance optimization, such as improving code performance or reducing memory usage."},
+	{Name: "test", Description: "Used for adding or updating tests."},
+}
 
 // PromptOptions 定义构建提示词所需的参数
+// 除 TemplateDirs 外，所有字段都会作为变量传给提示词模板，例如 {{.Diff}}、{{.Branch}}
 type PromptOptions struct {
-	Language              string // "cn" 或 "en"
-	Diff                  string // Git diff 内容
+	Language              string       // "cn" 或 "en"
+	Diff                  string       // Git diff 内容
+	Summary               string       // Go 代码变更的语义摘要，没有时为空
+	Dependencies          string       // 清单文件中依赖变化的列表，没有时为空
+	Submodules            string       // 子模块指针的变化及其间的提交，没有时为空
+	PreviousMessage       string       // 修改 (amend) 提交时原有的提交信息
+	SquashedCommits       []string     // 被压缩为一个提交的各提交标题，由旧到新
+	Stat                  string       // git diff --stat 输出
+	Branch                string       // 当前分支名
+	RecentCommits         []string     // 最近的提交标题，由新到旧
+	Types                 []CommitType // 可用的提交类型
 	WithDescription       bool
 	SubjectSeparateSymbol string
+	Convention            string   // 提交风格约定，见 Convention* 常量，为空时按 Conventional Commits 处理
+	Examples              []string // 作为示例的历史提交信息
+	Scope                 string   // 根据变更路径推断出的 scope，为空表示无法确定
+	Tickets               []string // 从分支名中提取的工单编号，会在生成后自动写入提交信息
+	RejectedMessage       string   // 被 git 钩子拒绝的提交信息
+	HookOutput            string   // 拒绝提交时钩子的输出
+	Operation             string   // 正在进行的 git 操作，见 Operation* 常量，为空表示普通提交
+	OperationMessage      string   // git 为该操作准备的提交信息，变基时为正在应用的提交的信息
+	MergedCommits         []string // 合并进来的提交标题，由旧到新
+	Conflicts             []string // 解决过冲突的文件，会在生成后自动列在正文末尾
+
+	// TemplateDirs 按优先级排列的模板查找目录，找不到时使用内置模板
+	TemplateDirs []string
 }
 
 const (
-	systemPromptTpl = `
+	// SystemTemplateName 和 UserTemplateName 是可覆盖的模板文件名
+	SystemTemplateName = "system.tmpl"
+	UserTemplateName   = "user.tmpl"
+)
+
+const (
+	defaultSystemTpl = `
 <role>
 You are an expert developer and git specialist.
 </role>
 <goal>
 Your task is to generate a concise and standardized git commit message based on the provided code changes (diff).
 </goal>
+{{- if and (or (eq .Convention "") (eq .Convention "conventional")) (ne .Operation "merge")}}
 <context>
 please follow below type definition
-- build: Used for modifying the project build system, such as changing dependencies, external interfaces, or upgrading Node versions.
-- chore: Used for modifying non-business code, such as changing build processes or tool configurations.
-- ci: Used for modifying the Continuous Integration process, such as changing Travis, Jenkins, or other workflow configurations.
-- docs: Used for modifying documentation, such as changing README files or API documentation.
-- style: Used for modifying code style, such as adjusting indentation, spaces, blank lines, etc.
-- refactor: Used for code refactoring, such as modifying code structure, variable names, or function names without changing functional logic.
-- perf: Used for performance optimization, such as improving code performance or reducing memory usage.
+{{- range .Types}}
+- {{.Name}}: {{.Description}}
+{{- end}}
 </context>
+{{- end}}
+{{- if .Examples}}
+<examples>
+Here are recent commit messages from this repository, match their format, tone and level of detail:
+{{- range .Examples}}
+---
+{{.}}
+{{- end}}
+</examples>
+{{- end}}
 <restriction>
+{{- if eq .Operation "merge"}}
+- This is a merge commit. The subject **MUST** be git's default merge subject exactly: "{{firstLine .OperationMessage}}".
+- Leave a blank line after the subject, then write a bulleted body ("- " prefix) summarizing what the merged commits bring in, each line **MUST** be less than 72 char.
+{{- else if eq .Operation "revert"}}
+- This commit reverts an earlier commit. The subject **MUST** be "revert: " followed by the subject of the reverted commit.
+- If the reason for the revert is visible from the changes, explain it in the body. The reference to the reverted commit is added automatically.
+{{- else if eq .Convention "gitmoji"}}
+- Start the subject with a single gitmoji that matches the change (e.g. ✨ for features, 🐛 for bug fixes), followed by a space and the subject.
+{{- else if eq .Convention "ticket"}}
+{{- if not .Tickets}}
+- Start the subject with a ticket prefix in the same format as the examples, using only ticket IDs known for this change. Never invent one, omit the prefix instead.
+{{- else}}
+- Write the subject in the same style as the examples, without the ticket prefix.
+{{- end}}
+{{- else if eq .Convention "freeform"}}
+- Write a short imperative subject line in the same style as the examples, without a type prefix.
+{{- else}}
 - Use the Conventional Commits format: <type>[optional scope]: <subject>
+{{- if .Scope}}
+- The scope **MUST** be "{{.Scope}}", it is inferred from the changed paths.
+{{- end}}
+{{- end}}
 - The subject line **MUST** be less than 100 characters.
-- If subject contains more than one topic, use %s to separate them.
+- If subject contains more than one topic, use {{.SubjectSeparateSymbol}} to separate them.
+{{- if .Tickets}}
+- This change belongs to {{join .Tickets ", "}}. The reference is added automatically, do NOT write it yourself and never mention any other ticket or issue ID.
+{{- end}}
 - Do NOT include markdown blocks (like ''' or code fences). Just return the raw message.
-%s
+{{- if eq .Language "cn"}}
+- The commit message **MUST** be written in Simplified Chinese (简体中文).
+{{- else}}
+- The commit message **MUST** be written in English.
+{{- end}}
+{{- if .Conflicts}}
+- The list of files with resolved conflicts is added automatically, do NOT write it yourself.
+{{- end}}
+{{- if eq .Operation "merge"}}
+{{- else if .SquashedCommits}}
+- Several commits are squashed into this one. Leave a blank line after the subject, then write a bulleted body ("- " prefix) listing the notable changes, each line **MUST** be less than 72 char.
+{{- else if .WithDescription}}
+- Provide a detailed description body around 3 - 5 lines, each line **MUST** be less than 72 char. Leave a blank line after the subject.
+{{- end}}
 </restriction>
 `
 
-	withDescriptionPrompt = "- Provide a detailed description body around 3 - 5 lines, each line **MUST** be less than 72 char. Leave a blank line after the subject."
-	langInstructionCN     = "- The commit message **MUST** be written in Simplified Chinese (简体中文)."
-	langInstructionEN     = "- The commit message **MUST** be written in English."
+	defaultUserTpl = `{{if .PreviousMessage -}}
+You are rewriting the message of an existing commit. Its current message is below, keep any details that are still accurate:
+
+{{.PreviousMessage}}
+
+{{end -}}
+{{if .HookOutput -}}
+The previous commit message was rejected by a git hook:
+
+{{.RejectedMessage}}
+
+Hook output:
+
+{{.HookOutput}}
+
+Write a new message for the same changes that fixes these problems.
+
+{{end -}}
+{{if eq .Operation "merge" -}}
+This commit concludes a merge. Git prepared this message:
+
+{{.OperationMessage}}
+{{if .MergedCommits}}
+The merge brings in these commits, from oldest to newest:
+{{range .MergedCommits}}
+- {{.}}
+{{- end}}
+{{end}}
+{{else if eq .Operation "revert" -}}
+This commit reverts an earlier commit. Git prepared this message:
+
+{{.OperationMessage}}
+
+{{else if eq .Operation "cherry-pick" -}}
+This commit is a cherry-pick of an existing commit, whose message is below. Keep it unless the changes below no longer match it:
+
+{{.OperationMessage}}
+
+{{else if and (eq .Operation "rebase") .OperationMessage -}}
+A rebase is in progress. The commit being replayed has the message below, keep the details that still match the changes:
+
+{{.OperationMessage}}
+
+{{end -}}
+{{if .Conflicts -}}
+Conflicts were resolved in these files, pay attention to how they were resolved:
+{{range .Conflicts}}
+- {{.}}
+{{- end}}
+
+{{end -}}
+{{if .SquashedCommits -}}
+These commits are squashed into one, from oldest to newest:
+{{range .SquashedCommits}}
+- {{.}}
+{{- end}}
+
+{{end -}}
+{{if .Summary -}}
+Here is a semantic summary of the Go API changes:
+
+{{.Summary}}
+
+{{end -}}
+{{if .Submodules -}}
+Here are the submodule pointer changes and the submodule commits between the old and new pointers, describe the update by what these commits change rather than by the hashes:
+
+{{.Submodules}}
+
+{{end -}}
+{{if .Dependencies -}}
+Here are the dependency changes parsed from the manifests (lockfile diffs are omitted):
+
+{{.Dependencies}}
+
+{{end -}}
+Here is the git diff output:
+
+{{.Diff}}`
 )
 
-func ConstructMessages(opts PromptOptions) []Message {
-	moreInstruction := langInstructionEN
-	if opts.Language == "cn" {
-		moreInstruction = langInstructionCN
+// ConstructMessages 渲染提示词模板并返回发送给模型的消息
+func ConstructMessages(opts PromptOptions) ([]Message, error) {
+	if opts.Types == nil {
+		opts.Types = DefaultTypes
 	}
 
-	if opts.WithDescription {
-		moreInstruction += "\n" + withDescriptionPrompt
+	return renderMessages(opts.TemplateDirs, opts.Convention, opts,
+		SystemTemplateName, defaultSystemTpl, UserTemplateName, defaultUserTpl)
+}
+
+// renderMessages 渲染一对 system / user 模板并组装为消息
+func renderMessages(dirs []string, convention string, data any, systemName, systemTpl, userName, userTpl string) ([]Message, error) {
+	// 1. 渲染 System Prompt
+	systemPrompt, err := renderTemplate(dirs, convention, data, systemName, systemTpl)
+	if err != nil {
+		return nil, err
 	}
 
-	// 2. 组装 System Prompt
-	finalSystemPrompt := fmt.Sprintf(systemPromptTpl, opts.SubjectSeparateSymbol, moreInstruction)
+	// 2. 渲染 User Prompt
+	userPrompt, err := renderTemplate(dirs, convention, data, userName, userTpl)
+	if err != nil {
+		return nil, err
+	}
 
 	// 3. 返回消息结构
 	return []Message{
-		{Role: "system", Content: finalSystemPrompt},
-		{Role: "user", Content: fmt.Sprintf("Here is the git diff output:\n\n%s", opts.Diff)},
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}, nil
+}
+
+// renderTemplate 按 dirs 的优先级查找模板文件，都不存在时使用内置模板
+// 每个目录中优先使用对应风格的模板 (例如 system.gitmoji.tmpl)，其次是通用模板 (system.tmpl)
+func renderTemplate(dirs []string, convention string, data any, name, fallback string) (string, error) {
+	names := []string{name}
+	if convention != "" {
+		base := strings.TrimSuffix(name, ".tmpl")
+		names = []string{base + "." + convention + ".tmpl", name}
+	}
+
+	text, err := lookupTemplate(dirs, names)
+	if err != nil {
+		return "", err
+	}
+	if text == "" {
+		text = fallback
+	}
+
+	tpl, err := template.New(name).Funcs(template.FuncMap{
+		"join":      strings.Join,
+		"firstLine": firstLine,
+	}).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse template %s failed: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render template %s failed: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+func lookupTemplate(dirs []string, names []string) (string, error) {
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		for _, name := range names {
+			content, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					continue
+				}
+				return "", err
+			}
+			return string(content), nil
+		}
 	}
+	return "", nil
 }
@@ -14,14 +14,11 @@ import (
 // ProviderConfig 定义初始化 Provider 所需的配置
 // 这些字段直接对应 config 包中的内容
 type ProviderConfig struct {
-	BaseURL               string
-	Path                  string
-	APIKey                string
-	Model                 string
-	Language              string
-	Timeout               time.Duration
-	WithDescription       bool
-	SubjectSeparateSymbol string
+	BaseURL string
+	Path    string
+	APIKey  string
+	Model   string
+	Timeout time.Duration
 }
 
 // genericProvider 是通用的 OpenAI 兼容协议实现
@@ -48,16 +45,18 @@ func NewProvider(cfg ProviderConfig) Client {
 	}
 }
 
-func (p *genericProvider) GenerateCommitMessage(ctx context.Context, diff string) (string, error) {
+func (p *genericProvider) GenerateCommitMessage(ctx context.Context, opts PromptOptions) (string, error) {
 	// 1. 利用 prompt.go 构建消息
-	messages := ConstructMessages(PromptOptions{
-		Language:              p.cfg.Language,
-		Diff:                  diff,
-		WithDescription:       p.cfg.WithDescription,
-		SubjectSeparateSymbol: p.cfg.SubjectSeparateSymbol,
-	})
-
-	// 2. 构建请求 Payload
+	messages, err := ConstructMessages(opts)
+	if err != nil {
+		return "", err
+	}
+
+	return p.Chat(ctx, messages)
+}
+
+func (p *genericProvider) Chat(ctx context.Context, messages []Message) (string, error) {
+	// 1. 构建请求 Payload
 	reqBody := ChatRequest{
 		Model:    p.cfg.Model,
 		Messages: messages,
@@ -81,7 +80,7 @@ func (p *genericProvider) GenerateCommitMessage(ctx context.Context, diff string
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("API request failed: %w", err)
+		return "", fmt.Errorf("API request failed: %w: %w", ErrNetwork, err)
 	}
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
@@ -93,7 +92,7 @@ func (p *genericProvider) GenerateCommitMessage(ctx context.Context, diff string
 	// 5. 处理响应
 	body, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return "", newAPIError(resp.StatusCode, body)
 	}
 
 	var result ChatResponse
@@ -0,0 +1,118 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SplitUnit 是拆分提交时可以被单独分配的最小变更单元，通常是一个 hunk 或整个文件
+type SplitUnit struct {
+	ID    string
+	File  string
+	Label string // 在界面中展示的简短描述
+	Patch string // 展示给模型的 diff 片段
+}
+
+// SplitGroup 是模型建议的一个原子提交
+type SplitGroup struct {
+	Message string
+	Units   []string // 包含的 SplitUnit.ID
+}
+
+const splitInstruction = `
+<split>
+The staged changes below are listed as numbered units. Group them into a small number of atomic commits,
+each commit containing closely related units only, and write a commit message for every commit following the rules above.
+Every unit **MUST** appear in exactly one commit.
+Return ONLY a JSON object without code fences, in this shape:
+{"commits": [{"message": "<commit message>", "units": ["1", "3"]}]}
+</split>
+`
+
+// ConstructSplitMessages 构建让模型把变更单元分组的消息，系统提示词沿用提交信息的模板
+func ConstructSplitMessages(opts PromptOptions, units []SplitUnit) ([]Message, error) {
+	messages, err := ConstructMessages(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("Here are the staged change units:\n")
+	for _, u := range units {
+		fmt.Fprintf(&b, "\n### unit %s (%s)\n%s\n", u.ID, u.File, strings.TrimRight(u.Patch, "\n"))
+	}
+
+	messages[0].Content += splitInstruction
+	messages[1].Content = b.String()
+	return messages, nil
+}
+
+// ProposeSplit 请求模型给出拆分方案
+func ProposeSplit(ctx context.Context, client Client, opts PromptOptions, units []SplitUnit) ([]SplitGroup, error) {
+	messages, err := ConstructSplitMessages(opts, units)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := client.Chat(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSplitPlan(reply, units)
+}
+
+// ParseSplitPlan 解析模型返回的拆分方案
+// 未知的单元会被忽略，重复出现的单元只保留第一次，遗漏的单元归入最后一个提交
+func ParseSplitPlan(reply string, units []SplitUnit) ([]SplitGroup, error) {
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("split plan is not JSON: %s", reply)
+	}
+
+	var plan struct {
+		Commits []struct {
+			Message string   `json:"message"`
+			Units   []string `json:"units"`
+		} `json:"commits"`
+	}
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &plan); err != nil {
+		return nil, fmt.Errorf("unmarshal split plan failed: %w", err)
+	}
+	if len(plan.Commits) == 0 {
+		return nil, fmt.Errorf("empty split plan from model")
+	}
+
+	known := make(map[string]bool, len(units))
+	for _, u := range units {
+		known[u.ID] = true
+	}
+
+	assigned := map[string]bool{}
+	var groups []SplitGroup
+	for _, c := range plan.Commits {
+		group := SplitGroup{Message: strings.TrimSpace(c.Message)}
+		for _, id := range c.Units {
+			if !known[id] || assigned[id] {
+				continue
+			}
+			assigned[id] = true
+			group.Units = append(group.Units, id)
+		}
+		if len(group.Units) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("split plan does not reference any unit")
+	}
+
+	last := &groups[len(groups)-1]
+	for _, u := range units {
+		if !assigned[u.ID] {
+			last.Units = append(last.Units, u.ID)
+		}
+	}
+	return groups, nil
+}
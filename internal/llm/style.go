@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// 仓库提交信息的风格约定
+const (
+	ConventionConventional = "conventional" // Conventional Commits: type(scope): subject
+	ConventionGitmoji      = "gitmoji"      // 以 emoji 或 :shortcode: 开头
+	ConventionTicket       = "ticket"       // 以工单号开头，例如 PROJ-123: subject
+	ConventionFreeform     = "freeform"     // 没有固定格式
+)
+
+var (
+	conventionalRe = regexp.MustCompile(`^[a-zA-Z]+(\([^)]*\))?!?: \S`)
+	gitmojiCodeRe  = regexp.MustCompile(`^:[a-z0-9_+-]+:`)
+	ticketRe       = regexp.MustCompile(`^\[?[A-Z][A-Z0-9]+-\d+\]?[:\s]`)
+)
+
+// DetectConvention 根据历史提交标题推断仓库使用的风格约定
+// 某种风格占比超过一半时采用该风格，否则视为自由格式；没有样本时返回空字符串
+func DetectConvention(subjects []string) string {
+	if len(subjects) == 0 {
+		return ""
+	}
+
+	counts := map[string]int{}
+	for _, s := range subjects {
+		counts[classifySubject(s)]++
+	}
+
+	for _, convention := range []string{ConventionConventional, ConventionGitmoji, ConventionTicket} {
+		if counts[convention]*2 > len(subjects) {
+			return convention
+		}
+	}
+	return ConventionFreeform
+}
+
+// DetectLanguage 根据历史提交标题推断提交语言，返回 "cn" 或 "en"；没有样本时返回空字符串
+func DetectLanguage(subjects []string) string {
+	if len(subjects) == 0 {
+		return ""
+	}
+
+	chinese := 0
+	for _, s := range subjects {
+		for _, r := range s {
+			if unicode.Is(unicode.Han, r) {
+				chinese++
+				break
+			}
+		}
+	}
+	if chinese*2 > len(subjects) {
+		return "cn"
+	}
+	return "en"
+}
+
+func classifySubject(subject string) string {
+	subject = strings.TrimSpace(subject)
+	switch {
+	case conventionalRe.MatchString(subject):
+		return ConventionConventional
+	case gitmojiCodeRe.MatchString(subject) || startsWithEmoji(subject):
+		return ConventionGitmoji
+	case ticketRe.MatchString(subject):
+		return ConventionTicket
+	}
+	return ConventionFreeform
+}
+
+func startsWithEmoji(s string) bool {
+	for _, r := range s {
+		return unicode.Is(unicode.So, r) || (r >= 0x1F000 && r <= 0x1FAFF)
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 工单编号在提交信息中的位置
+const (
+	TicketPlacementPrefix  = "prefix"  // 标题开头，例如 "PROJ-1234 feat: add login"
+	TicketPlacementTrailer = "trailer" // 末尾的 Refs: 脚注
+)
+
+// trailerRe 匹配 git trailer 行，例如 "Signed-off-by: Name <mail>"
+var trailerRe = regexp.MustCompile(`^[A-Za-z0-9-]+: `)
+
+// ApplyTickets 返回一个把工单编号写入提交信息的后处理函数
+// 已经出现在提交信息中的编号不会重复添加
+func ApplyTickets(ids []string, placement string) func(string) string {
+	return func(msg string) string {
+		msg = strings.TrimSpace(msg)
+		var missing []string
+		for _, id := range ids {
+			if !containsTicket(msg, id) {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) == 0 {
+			return msg
+		}
+
+		if placement == TicketPlacementTrailer {
+			return appendTrailer(msg, "Refs: "+strings.Join(missing, ", "))
+		}
+		return strings.Join(missing, " ") + " " + msg
+	}
+}
+
+// containsTicket 判断 msg 中是否有完整的工单编号 id，PROJ-123 不算包含 PROJ-12
+func containsTicket(msg, id string) bool {
+	re, err := regexp.Compile(`(^|[^\w-])` + regexp.QuoteMeta(id) + `($|[^\w])`)
+	if err != nil {
+		return strings.Contains(msg, id)
+	}
+	return re.MatchString(msg)
+}
+
+// appendTrailer 在提交信息末尾追加一行 trailer，最后一段已经是 trailer 时追加到同一段
+func appendTrailer(msg, trailer string) string {
+	paragraphs := strings.Split(msg, "\n\n")
+	last := paragraphs[len(paragraphs)-1]
+	if len(paragraphs) > 1 && isTrailerBlock(last) {
+		return msg + "\n" + trailer
+	}
+	return msg + "\n\n" + trailer
+}
+
+func isTrailerBlock(paragraph string) bool {
+	for _, line := range strings.Split(strings.TrimSpace(paragraph), "\n") {
+		if !trailerRe.MatchString(line) {
+			return false
+		}
+	}
+	return true
+}
+
+// stripTickets 去掉标题开头的工单编号，用于检查其余部分的格式
+func stripTickets(subject string, ids []string) string {
+	for {
+		trimmed := subject
+		for _, id := range ids {
+			trimmed = strings.TrimPrefix(trimmed, id+" ")
+		}
+		if trimmed == subject {
+			return subject
+		}
+		subject = trimmed
+	}
+}
@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"aicommits/internal/conventional"
+)
+
+const (
+	maxSubjectLength  = 100
+	maxBodyLineLength = 72
+)
+
+// Validate 检查提交信息是否符合提示词中的约束，返回发现的问题，没有问题时返回 nil
+func Validate(msg string, opts PromptOptions) []string {
+	msg = strings.TrimSpace(msg)
+	if msg == "" {
+		return []string{"提交信息为空"}
+	}
+
+	var problems []string
+	lines := strings.Split(msg, "\n")
+	subject := lines[0]
+
+	if utf8.RuneCountInString(subject) >= maxSubjectLength {
+		problems = append(problems, fmt.Sprintf("标题长度应小于 %d 个字符", maxSubjectLength))
+	}
+
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		problems = append(problems, "标题和正文之间应空一行")
+	}
+	if opts.WithDescription {
+		for _, line := range lines[1:] {
+			if utf8.RuneCountInString(line) >= maxBodyLineLength {
+				problems = append(problems, fmt.Sprintf("正文每行应小于 %d 个字符", maxBodyLineLength))
+				break
+			}
+		}
+	}
+
+	// 合并提交使用 git 默认的标题，不检查提交风格
+	if opts.Operation != OperationMerge && (opts.Convention == "" || opts.Convention == ConventionConventional) {
+		problems = append(problems, validateConventional(stripTickets(subject, opts.Tickets), opts)...)
+	}
+	return problems
+}
+
+func validateConventional(subject string, opts PromptOptions) []string {
+	commit, ok := conventional.Parse(subject)
+	if !ok {
+		return []string{"标题不符合 Conventional Commits 格式: <type>[optional scope]: <subject>"}
+	}
+
+	var problems []string
+	commitType, scope := commit.Type, commit.Scope
+
+	types := opts.Types
+	if types == nil {
+		types = DefaultTypes
+	}
+	// revert 不在常用类型中，但撤销提交时总是使用它
+	known := opts.Operation == OperationRevert && commitType == OperationRevert
+	for _, t := range types {
+		if t.Name == commitType {
+			known = true
+			break
+		}
+	}
+	if !known {
+		problems = append(problems, fmt.Sprintf("未知的提交类型: %s", commitType))
+	}
+
+	if opts.Scope != "" && scope != opts.Scope && opts.Operation != OperationRevert {
+		problems = append(problems, fmt.Sprintf("scope 应为 %s (根据变更路径推断)", opts.Scope))
+	}
+	return problems
+}
@@ -0,0 +1,168 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"aicommits/internal/conventional"
+)
+
+// versionRe 匹配 [v]MAJOR.MINOR.PATCH[-PRERELEASE][+BUILD]
+var versionRe = regexp.MustCompile(`^(v?)(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// Version 是一个语义化版本号
+type Version struct {
+	Prefix string // 保留 tag 中的 "v" 前缀
+	Major  int
+	Minor  int
+	Patch  int
+	Pre    string // 预发布标识，例如 "rc.1"，正式版本为空
+}
+
+// Parse 解析 tag 名称，不是语义化版本时返回 false
+func Parse(s string) (Version, bool) {
+	m := versionRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Version{}, false
+	}
+	major, _ := strconv.Atoi(m[2])
+	minor, _ := strconv.Atoi(m[3])
+	patch, _ := strconv.Atoi(m[4])
+	return Version{Prefix: m[1], Major: major, Minor: minor, Patch: patch, Pre: m[5]}, true
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%s%d.%d.%d", v.Prefix, v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	return s
+}
+
+// IsPrerelease 判断是否是预发布版本
+func (v Version) IsPrerelease() bool {
+	return v.Pre != ""
+}
+
+// Compare 按语义化版本的优先级比较，返回 -1、0 或 1
+func (v Version) Compare(o Version) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	switch {
+	case v.Pre == o.Pre:
+		return 0
+	case v.Pre == "":
+		return 1
+	case o.Pre == "":
+		return -1
+	}
+	return comparePre(v.Pre, o.Pre)
+}
+
+// comparePre 逐段比较预发布标识，数字段按数值比较且低于字母段
+func comparePre(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(as) - len(bs))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// Level 是版本号需要提升的级别
+type Level int
+
+const (
+	None Level = iota
+	Patch
+	Minor
+	Major
+)
+
+func (l Level) String() string {
+	switch l {
+	case Major:
+		return "major"
+	case Minor:
+		return "minor"
+	case Patch:
+		return "patch"
+	}
+	return "none"
+}
+
+// Impact 根据提交信息判断它要求的提升级别：
+// 破坏性变更为 major，feat 为 minor，其余 (包括不符合约定的提交) 为 patch
+func Impact(msg string) Level {
+	c, ok := conventional.Parse(msg)
+	switch {
+	case ok && c.Breaking:
+		return Major
+	case ok && c.Type == "feat":
+		return Minor
+	}
+	return Patch
+}
+
+// Bump 返回按 level 提升后的正式版本
+func (v Version) Bump(level Level) Version {
+	next := Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	switch level {
+	case Major:
+		next.Major, next.Minor, next.Patch = v.Major+1, 0, 0
+	case Minor:
+		next.Minor, next.Patch = v.Minor+1, 0
+	case Patch:
+		next.Patch = v.Patch + 1
+	}
+	return next
+}
+
+// Prerelease 返回 v 的下一个预发布版本 (例如 1.3.0-rc.2)
+// existing 是已有的版本号，用于找到同一版本、同一标识下最大的序号
+func (v Version) Prerelease(id string, existing []Version) Version {
+	n := 0
+	for _, e := range existing {
+		if e.Major != v.Major || e.Minor != v.Minor || e.Patch != v.Patch {
+			continue
+		}
+		rest, ok := strings.CutPrefix(e.Pre, id+".")
+		if !ok {
+			continue
+		}
+		if seq, err := strconv.Atoi(rest); err == nil && seq > n {
+			n = seq
+		}
+	}
+	v.Pre = fmt.Sprintf("%s.%d", id, n+1)
+	return v
+}
@@ -0,0 +1,396 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// BatchItem 是批量模式中的一个仓库
+type BatchItem struct {
+	Name     string
+	Branch   string
+	Generate func(ctx context.Context) (string, error)
+	Commit   func(msg string) (string, error) // 返回 git commit 的输出
+}
+
+// BatchResult 是一个仓库最终的状态
+type BatchResult struct {
+	Name      string
+	Msg       string
+	Selected  bool   // 用户确认提交
+	Committed bool   // 已提交成功
+	Cancelled bool   // 选中了，但在开始提交之前被中断
+	Unknown   bool   // 强制退出时仍在提交，结果未知
+	Output    string // git commit 的输出
+	Err       error  // 生成或提交失败的错误
+}
+
+type batchRowState int
+
+const (
+	rowGenerating batchRowState = iota
+	rowReady
+	rowFailed
+	rowCommitting
+	rowCommitted
+	rowCommitFailed
+	rowCancelled
+)
+
+type batchRow struct {
+	state    batchRowState
+	msg      string
+	err      error
+	output   string
+	selected bool
+}
+
+type batchGeneratedMsg struct {
+	index int
+	msg   string
+	err   error
+}
+
+type batchCommittedMsg struct {
+	index  int
+	output string
+	err    error
+}
+
+// BatchModel 在一张表中同时生成多个仓库的提交信息，确认后提交选中的仓库
+type BatchModel struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	items  []BatchItem
+	rows   []batchRow
+	jobs   chan struct{} // 限制同时进行的生成和提交数量
+
+	cursor    int
+	editing   bool
+	textInput textinput.Model
+	spinner   spinner.Model
+	notice    string
+
+	committing  bool
+	pending     int  // 正在提交的仓库数量
+	interrupted bool // 提交过程中按了 Ctrl+C，等待进行中的提交完成后退出
+
+	Confirmed bool // 已确认并完成提交
+}
+
+// NewBatchModel 创建批量模式的界面，jobs 是同时进行的请求数量上限
+func NewBatchModel(ctx context.Context, items []BatchItem, jobs int) BatchModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+
+	ti := textinput.New()
+	ti.Placeholder = "在此编辑提交信息..."
+	ti.CharLimit = 0
+	ti.Width = 100
+
+	ctx, cancel := context.WithCancel(ctx)
+	return BatchModel{
+		ctx:       ctx,
+		cancel:    cancel,
+		items:     items,
+		rows:      make([]batchRow, len(items)),
+		jobs:      make(chan struct{}, max(jobs, 1)),
+		textInput: ti,
+		spinner:   s,
+	}
+}
+
+// Results 返回每个仓库的最终状态，顺序与传入的 items 一致
+func (m BatchModel) Results() []BatchResult {
+	results := make([]BatchResult, len(m.items))
+	for i, row := range m.rows {
+		results[i] = BatchResult{
+			Name:      m.items[i].Name,
+			Msg:       row.msg,
+			Selected:  row.selected,
+			Committed: row.state == rowCommitted,
+			Cancelled: row.state == rowCancelled,
+			Unknown:   row.state == rowCommitting,
+			Output:    row.output,
+			Err:       row.err,
+		}
+	}
+	return results
+}
+
+// CommitStarted 表示已经开始提交，此时即使被中断也应该输出每个仓库的结果
+func (m BatchModel) CommitStarted() bool {
+	return m.committing
+}
+
+func (m BatchModel) Init() tea.Cmd {
+	cmds := []tea.Cmd{m.spinner.Tick}
+	for i := range m.items {
+		cmds = append(cmds, m.generateCmd(i))
+	}
+	return tea.Batch(cmds...)
+}
+
+// acquire 等待空闲的并发名额，界面取消时返回 false
+func (m BatchModel) acquire() bool {
+	select {
+	case m.jobs <- struct{}{}:
+		// 等待期间可能已经被取消
+		if m.ctx.Err() != nil {
+			<-m.jobs
+			return false
+		}
+		return true
+	case <-m.ctx.Done():
+		return false
+	}
+}
+
+func (m BatchModel) generateCmd(i int) tea.Cmd {
+	return func() tea.Msg {
+		if !m.acquire() {
+			return batchGeneratedMsg{index: i, err: m.ctx.Err()}
+		}
+		defer func() { <-m.jobs }()
+		msg, err := m.items[i].Generate(m.ctx)
+		return batchGeneratedMsg{index: i, msg: msg, err: err}
+	}
+}
+
+func (m BatchModel) commitCmd(i int, msg string) tea.Cmd {
+	return func() tea.Msg {
+		if !m.acquire() {
+			return batchCommittedMsg{index: i, err: m.ctx.Err()}
+		}
+		defer func() { <-m.jobs }()
+		output, err := m.items[i].Commit(msg)
+		return batchCommittedMsg{index: i, output: output, err: err}
+	}
+}
+
+func (m BatchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.editing {
+			switch msg.String() {
+			case "enter", "esc":
+				m.rows[m.cursor].msg = m.textInput.Value()
+				m.editing = false
+				return m, nil
+			}
+			m.textInput, cmd = m.textInput.Update(msg)
+			return m, cmd
+		}
+		// 提交过程中按 Ctrl+C 不再开始新的提交，等待进行中的提交完成；再按一次强制退出
+		if m.committing {
+			if msg.String() == "ctrl+c" {
+				if m.interrupted {
+					return m, tea.Quit
+				}
+				m.interrupted = true
+				m.cancel()
+				m.notice = "已中断，正在等待进行中的提交完成 (再次按 Ctrl+C 强制退出)"
+			}
+			return m, nil
+		}
+
+		m.notice = ""
+		row := &m.rows[m.cursor]
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			m.cancel()
+			return m, tea.Quit
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.rows)-1 {
+				m.cursor++
+			}
+		case " ":
+			if row.state == rowReady {
+				row.selected = !row.selected
+			}
+		case "e":
+			if row.state == rowReady {
+				m.editing = true
+				m.textInput.SetValue(row.msg)
+				m.textInput.CursorEnd()
+				m.textInput.Focus()
+				return m, textinput.Blink
+			}
+		case "r":
+			if row.state == rowReady || row.state == rowFailed {
+				*row = batchRow{state: rowGenerating}
+				return m, tea.Batch(m.spinner.Tick, m.generateCmd(m.cursor))
+			}
+		case "enter":
+			return m.commitSelected()
+		}
+		return m, nil
+
+	case batchGeneratedMsg:
+		row := &m.rows[msg.index]
+		if msg.err != nil {
+			*row = batchRow{state: rowFailed, err: msg.err}
+		} else {
+			*row = batchRow{state: rowReady, msg: msg.msg, selected: true}
+		}
+		return m, nil
+
+	case batchCommittedMsg:
+		row := &m.rows[msg.index]
+		row.output = msg.output
+		switch {
+		case errors.Is(msg.err, context.Canceled):
+			row.state = rowCancelled
+		case msg.err != nil:
+			row.state, row.err = rowCommitFailed, msg.err
+		default:
+			row.state = rowCommitted
+		}
+		m.pending--
+		if m.pending == 0 {
+			m.Confirmed = !m.interrupted
+			return m, tea.Quit
+		}
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.committing || m.generating() {
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+// generating 表示还有仓库正在生成提交信息
+func (m BatchModel) generating() bool {
+	for _, row := range m.rows {
+		if row.state == rowGenerating {
+			return true
+		}
+	}
+	return false
+}
+
+// commitSelected 开始提交所有选中的仓库，还有仓库在生成时等待
+func (m BatchModel) commitSelected() (tea.Model, tea.Cmd) {
+	if m.generating() {
+		m.notice = "还有仓库正在生成提交信息，请稍候"
+		return m, nil
+	}
+
+	var cmds []tea.Cmd
+	for i := range m.rows {
+		row := &m.rows[i]
+		if row.state == rowReady && row.selected {
+			row.state = rowCommitting
+			cmds = append(cmds, m.commitCmd(i, row.msg))
+		}
+	}
+	if len(cmds) == 0 {
+		m.notice = "没有选中任何仓库"
+		return m, nil
+	}
+	m.committing = true
+	m.pending = len(cmds)
+	return m, tea.Batch(append(cmds, m.spinner.Tick)...)
+}
+
+func (m BatchModel) View() string {
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	tipsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).MarginTop(1)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	okStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	noticeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+	if m.editing {
+		return fmt.Sprintf(
+			"\n 编辑 %s 的提交信息 (Enter 保存):\n\n %s\n\n",
+			m.items[m.cursor].Name,
+			m.textInput.View(),
+		)
+	}
+
+	nameWidth, branchWidth := 0, 0
+	selected := 0
+	for i, item := range m.items {
+		nameWidth = max(nameWidth, lipgloss.Width(item.Name))
+		branchWidth = max(branchWidth, lipgloss.Width(item.Branch))
+		if m.rows[i].selected {
+			selected++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n 批量提交 (%d 个仓库，已选 %d 个):\n\n", len(m.items), selected)
+	for i, item := range m.items {
+		row := m.rows[i]
+		pointer := "  "
+		if i == m.cursor {
+			pointer = cursorStyle.Render("▸ ")
+		}
+		check := "[ ]"
+		if row.selected {
+			check = "[✔]"
+		}
+
+		var status string
+		switch row.state {
+		case rowGenerating:
+			status = m.spinner.View() + " 正在生成..."
+		case rowFailed:
+			status = errStyle.Render(fmt.Sprintf("❌ 生成失败: %v", row.err))
+		case rowCommitting:
+			status = m.spinner.View() + " 正在提交..."
+		case rowCommitted:
+			status = okStyle.Render("✅ " + firstLine(row.msg))
+		case rowCommitFailed:
+			status = errStyle.Render("❌ 提交失败")
+		case rowCancelled:
+			status = dimStyle.Render("已中断，未提交")
+		default:
+			status = firstLine(row.msg)
+		}
+		fmt.Fprintf(&b, " %s%s %-*s  %s  %s\n", pointer, check,
+			nameWidth, item.Name, dimStyle.Render(fmt.Sprintf("%-*s", branchWidth, item.Branch)), status)
+	}
+
+	// 当前仓库的完整提交信息
+	if row := m.rows[m.cursor]; row.msg != "" {
+		boxStyle := lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("63")).
+			Padding(0, 1).
+			Width(messageBoxWidth)
+		b.WriteString("\n" + boxStyle.Render(row.msg) + "\n")
+	}
+	if m.notice != "" {
+		b.WriteString("\n " + noticeStyle.Render(m.notice) + "\n")
+	}
+	if !m.committing {
+		b.WriteString(tipsStyle.Render("Move: [↑/↓] | Toggle: [Space] | Edit: [e] | Retry: [r] | Commit selected: [Enter] | Cancel: [Esc]"))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// firstLine 返回提交信息的标题
+func firstLine(msg string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+	return line
+}
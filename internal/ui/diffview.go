@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"aicommits/internal/diff"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxListFiles 是 diff 上方文件列表最多显示的文件数，文件更多时跟随当前文件滚动
+const maxListFiles = 5
+
+var (
+	diffFileStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("63"))
+	diffHunkStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	diffAddStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	diffDelStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	diffMetaStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	diffCursorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+)
+
+// diffView 是预览界面中可以滚动的 diff，上方的文件列表用于在文件之间跳转
+type diffView struct {
+	files    []diff.File
+	offsets  []int // 每个文件在内容中的起始行
+	viewport viewport.Model
+}
+
+func newDiffView(raw string) diffView {
+	d := diffView{files: diff.Parse(raw), viewport: viewport.New(0, 0)}
+	d.viewport.SetHorizontalStep(4)
+
+	var lines []string
+	for _, f := range d.files {
+		d.offsets = append(d.offsets, len(lines))
+		lines = append(lines, renderDiffFile(f)...)
+	}
+	d.viewport.SetContent(strings.Join(lines, "\n"))
+	return d
+}
+
+// renderDiffFile 按 diff 语法为一个文件着色：文件标题、hunk 头、新增和删除的行
+func renderDiffFile(f diff.File) []string {
+	added, deleted := f.Stat()
+	title := f.Path()
+	switch {
+	case f.IsNew():
+		title += " (新文件)"
+	case f.IsDeleted():
+		title += " (已删除)"
+	case f.OldPath != f.NewPath:
+		title = f.OldPath + " → " + f.NewPath
+	}
+	lines := []string{diffFileStyle.Render(fmt.Sprintf("── %s +%d -%d ──", title, added, deleted))}
+
+	for _, h := range f.Hunks {
+		lines = append(lines, diffHunkStyle.Render(h.Header))
+		for _, line := range h.Lines {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				line = diffAddStyle.Render(line)
+			case strings.HasPrefix(line, "-"):
+				line = diffDelStyle.Render(line)
+			case strings.HasPrefix(line, `\`):
+				line = diffMetaStyle.Render(line)
+			}
+			lines = append(lines, line)
+		}
+	}
+	// 二进制文件和只修改权限的文件没有 hunk，显示 diff 头中的说明
+	if len(f.Hunks) == 0 {
+		for _, line := range f.Header[min(1, len(f.Header)):] {
+			lines = append(lines, diffMetaStyle.Render(line))
+		}
+	}
+	return append(lines, "")
+}
+
+// empty 表示没有可以显示的 diff
+func (d diffView) empty() bool {
+	return len(d.files) == 0
+}
+
+// listHeight 返回文件列表占用的行数
+func (d diffView) listHeight() int {
+	return min(len(d.files), maxListFiles) + 1
+}
+
+// setSize 设置整个 diff 区域 (文件列表 + diff) 的大小
+func (d *diffView) setSize(width, height int) {
+	d.viewport.Width = width
+	d.viewport.Height = max(height-d.listHeight(), 1)
+	// 高度变化后重新限制滚动位置
+	d.viewport.SetYOffset(d.viewport.YOffset)
+}
+
+// current 返回当前显示在顶部的文件
+func (d diffView) current() int {
+	cur := 0
+	for i, offset := range d.offsets {
+		if offset <= d.viewport.YOffset {
+			cur = i
+		}
+	}
+	return cur
+}
+
+// jump 滚动到第 i 个文件，超出范围时循环
+func (d *diffView) jump(i int) {
+	if d.empty() {
+		return
+	}
+	i = (i + len(d.files)) % len(d.files)
+	d.viewport.SetYOffset(d.offsets[i])
+}
+
+func (d diffView) update(msg tea.KeyMsg) (diffView, tea.Cmd) {
+	switch msg.String() {
+	case "tab", "]":
+		d.jump(d.current() + 1)
+		return d, nil
+	case "shift+tab", "[":
+		d.jump(d.current() - 1)
+		return d, nil
+	}
+	var cmd tea.Cmd
+	d.viewport, cmd = d.viewport.Update(msg)
+	return d, cmd
+}
+
+func (d diffView) view() string {
+	cur := d.current()
+	// 文件太多时只显示当前文件附近的一段
+	start := max(min(cur-maxListFiles/2, len(d.files)-maxListFiles), 0)
+	end := min(start+maxListFiles, len(d.files))
+
+	var b strings.Builder
+	b.WriteString(diffMetaStyle.Render(fmt.Sprintf("文件 %d/%d  %3.0f%%", cur+1, len(d.files), d.viewport.ScrollPercent()*100)))
+	b.WriteString("\n")
+	for i := start; i < end; i++ {
+		f := d.files[i]
+		added, deleted := f.Stat()
+		line := fmt.Sprintf("%s %s %s", f.Path(),
+			diffAddStyle.Render(fmt.Sprintf("+%d", added)), diffDelStyle.Render(fmt.Sprintf("-%d", deleted)))
+		if i == cur {
+			line = diffCursorStyle.Render("▸ ") + line
+		} else {
+			line = "  " + line
+		}
+		b.WriteString(lipgloss.NewStyle().MaxWidth(d.viewport.Width).Render(line))
+		b.WriteString("\n")
+	}
+	b.WriteString(d.viewport.View())
+	return b.String()
+}
@@ -3,9 +3,12 @@ package ui
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"aicommits/internal/llm"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput" // 1. 引入 textinput
 	tea "github.com/charmbracelet/bubbletea"
@@ -14,28 +17,68 @@ import (
 
 type sessionState int
 
+// 预览界面的布局：提交信息框的宽度，以及终端至少多宽时把 diff 放在提交信息右侧
+const (
+	messageBoxWidth = 60
+	sideBySideWidth = 120
+)
+
 const (
 	stateLoading sessionState = iota
 	stateReview
 	stateEditing
 	stateError
+	stateCoAuthors
+	stateCommitting
+	stateCommitFailed
+	stateSwitching
 )
 
+// Alternative 是生成失败时可以切换的模型或配置
+type Alternative struct {
+	Name   string
+	Client llm.Client
+}
+
+// CommitFunc 使用确认后的提交信息和选中的共同作者执行提交，返回 git commit 的输出
+type CommitFunc func(msg string, coAuthors []string) (string, error)
+
 type Model struct {
 	client llm.Client
-	diff   string
+	opts   llm.PromptOptions
 	ctx    context.Context
 
+	// 每次生成使用独立的 context，按 Esc / Ctrl+C 时取消正在进行的请求
+	cancel     context.CancelFunc
+	generation int // 当前请求的序号，用于丢弃已取消请求的结果
+	started    time.Time
+
 	state     sessionState
 	Msg       string
+	warnings  []string // 校验提交信息时发现的问题
 	err       error
 	spinner   spinner.Model
 	textInput textinput.Model // 2. 改为 textInput
 
+	candidates []string // 可选的共同作者
+	selected   []bool
+	cursor     int
+
+	commit CommitFunc // 为空时确认后直接退出，由调用方提交
+
+	alternatives []Alternative
+	altCursor    int
+	notice       string // 复制提示词、切换模型等操作的结果
+
+	diff          diffView // 预览界面中与提交信息对照的 diff
+	width, height int      // 终端大小，收到 tea.WindowSizeMsg 后更新
+
 	Confirmed bool
+	Committed bool   // 已在界面中提交成功
+	Output    string // 最近一次 git commit 的输出，包括钩子的输出
 }
 
-func NewModel(ctx context.Context, client llm.Client, diff string) Model {
+func NewModel(ctx context.Context, client llm.Client, opts llm.PromptOptions) Model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
@@ -49,16 +92,72 @@ func NewModel(ctx context.Context, client llm.Client, diff string) Model {
 
 	return Model{
 		client:    client,
-		diff:      diff,
+		opts:      opts,
 		ctx:       ctx,
 		state:     stateLoading,
+		started:   time.Now(),
 		spinner:   s,
 		textInput: ti,
 	}
 }
 
+// WithCoAuthors 设置可在预览界面中选择的共同作者 ("Name <email>")
+func (m Model) WithCoAuthors(candidates []string) Model {
+	m.candidates = candidates
+	m.selected = make([]bool, len(candidates))
+	return m
+}
+
+// CoAuthors 返回用户选中的共同作者
+func (m Model) CoAuthors() []string {
+	var authors []string
+	for i, ok := range m.selected {
+		if ok {
+			authors = append(authors, m.candidates[i])
+		}
+	}
+	return authors
+}
+
+// Err 返回生成或提交失败的错误，没有失败时返回 nil
+func (m Model) Err() error {
+	return m.err
+}
+
+// WithAlternatives 设置生成失败时可以切换的模型
+func (m Model) WithAlternatives(alternatives []Alternative) Model {
+	m.alternatives = alternatives
+	return m
+}
+
+// WithDiff 设置在预览界面中与提交信息对照显示的 diff，应当是未经截断或摘要的原始 diff
+func (m Model) WithDiff(raw string) Model {
+	m.diff = newDiffView(raw)
+	return m
+}
+
+// WithCommit 让确认后的提交在界面中执行，提交或钩子失败时可以编辑、重试或让模型修正
+func (m Model) WithCommit(commit CommitFunc) Model {
+	m.commit = commit
+	return m
+}
+
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(m.spinner.Tick, m.generateMsgCmd)
+	return func() tea.Msg { return startMsg{} }
+}
+
+// generate 取消上一次请求并开始新的生成
+func (m Model) generate() (Model, tea.Cmd) {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.cancel = cancel
+	m.generation++
+	m.started = time.Now()
+	m.state = stateLoading
+	m.err = nil
+	return m, tea.Batch(m.spinner.Tick, m.generateMsgCmd(ctx, m.generation, m.opts))
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -76,26 +175,145 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Quit
 			case "enter":
 				m.Confirmed = true
-				return m, tea.Quit
+				if m.commit == nil {
+					return m, tea.Quit
+				}
+				m.state = stateCommitting
+				return m, tea.Batch(m.spinner.Tick, m.commitCmd)
 			case "r":
-				m.state = stateLoading
-				m.Msg = ""
-				return m, tea.Batch(m.spinner.Tick, m.generateMsgCmd)
+				return m.generate()
 			case "e":
 				m.state = stateEditing
 				// 进入编辑模式时，把当前消息填进去，并把光标移到最后
 				m.textInput.SetValue(m.Msg)
 				m.textInput.CursorEnd()
 				return m, textinput.Blink
+			case "a":
+				if len(m.candidates) > 0 {
+					m.state = stateCoAuthors
+				}
+				return m, nil
+			}
+			// 其余按键用于滚动 diff 和在文件之间跳转
+			if m.showDiff() {
+				m.diff.setSize(m.diffSize(m.reviewMessage()))
+				m.diff, cmd = m.diff.update(msg)
+				return m, cmd
 			}
 
+		// --- 选择共同作者 ---
+		case stateCoAuthors:
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "up", "k":
+				if m.cursor > 0 {
+					m.cursor--
+				}
+			case "down", "j":
+				if m.cursor < len(m.candidates)-1 {
+					m.cursor++
+				}
+			case " ":
+				m.selected[m.cursor] = !m.selected[m.cursor]
+			case "enter", "esc":
+				m.state = stateReview
+			}
+			return m, nil
+
+		// --- 提交失败 ---
+		case stateCommitFailed:
+			switch msg.String() {
+			case "q", "ctrl+c", "esc":
+				return m, tea.Quit
+			case "enter":
+				m.state = stateCommitting
+				return m, tea.Batch(m.spinner.Tick, m.commitCmd)
+			case "e":
+				m.state = stateEditing
+				m.textInput.SetValue(m.Msg)
+				m.textInput.CursorEnd()
+				return m, textinput.Blink
+			case "f":
+				// 把被拒绝的信息和钩子输出交给模型修正
+				m.opts.RejectedMessage = m.Msg
+				m.opts.HookOutput = m.Output
+				return m.generate()
+			}
+			return m, nil
+
+		// --- 生成失败 ---
+		case stateError:
+			m.notice = ""
+			switch msg.String() {
+			case "q", "ctrl+c", "esc":
+				return m, tea.Quit
+			case "r":
+				return m.generate()
+			case "m":
+				if len(m.alternatives) > 0 {
+					m.state = stateSwitching
+				} else {
+					m.notice = "没有其他可用的模型，可以在配置文件的 profiles 中添加"
+				}
+			case "e":
+				// 手动编写提交信息
+				m.state = stateEditing
+				m.textInput.SetValue(m.Msg)
+				m.textInput.CursorEnd()
+				return m, textinput.Blink
+			case "c":
+				m.notice = m.copyPrompt()
+			}
+			return m, nil
+
+		// --- 切换模型 ---
+		case stateSwitching:
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "up", "k":
+				if m.altCursor > 0 {
+					m.altCursor--
+				}
+			case "down", "j":
+				if m.altCursor < len(m.alternatives)-1 {
+					m.altCursor++
+				}
+			case "enter":
+				m.client = m.alternatives[m.altCursor].Client
+				return m.generate()
+			case "esc":
+				m.state = stateError
+			}
+			return m, nil
+
+		// --- 生成中 ---
+		case stateLoading:
+			switch msg.String() {
+			case "ctrl+c":
+				m.cancel()
+				return m, tea.Quit
+			case "esc":
+				// 取消请求，已有生成结果时回到预览，否则退出
+				m.cancel()
+				if m.Msg != "" {
+					m.state = stateReview
+					return m, nil
+				}
+				return m, tea.Quit
+			}
+			return m, nil
+
 		// --- 编辑状态 ---
 		case stateEditing:
 			switch msg.String() {
 			// 4. 单行模式下，回车(Enter)通常意味着“完成编辑”
 			case "enter", "esc":
 				m.Msg = m.textInput.Value() // 保存修改
-				m.state = stateReview       //以此返回预览界面
+				m.warnings = llm.Validate(m.Msg, m.opts)
+				m.err = nil
+				m.state = stateReview //以此返回预览界面
 				return m, nil
 			}
 			// 透传按键给输入框
@@ -103,18 +321,44 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+
+	case startMsg:
+		return m.generate()
+
 	case generatedMsg:
+		if msg.generation != m.generation || m.state != stateLoading {
+			return m, nil
+		}
+		m.cancel()
 		m.state = stateReview
-		m.Msg = string(msg)
+		m.Msg = msg.msg
+		m.warnings = llm.Validate(m.Msg, m.opts)
 		return m, nil
 
-	case errMsg:
+	case generateErrMsg:
+		if msg.generation != m.generation || m.state != stateLoading {
+			return m, nil
+		}
+		m.cancel()
 		m.state = stateError
-		m.err = error(msg)
+		m.err = msg.err
+		return m, nil
+
+	case commitResultMsg:
+		m.Output = msg.output
+		if msg.err != nil {
+			m.state = stateCommitFailed
+			m.err = msg.err
+			return m, nil
+		}
+		m.Committed = true
 		return m, tea.Quit
 
 	case spinner.TickMsg:
-		if m.state == stateLoading {
+		if m.state == stateLoading || m.state == stateCommitting {
 			m.spinner, cmd = m.spinner.Update(msg)
 			return m, cmd
 		}
@@ -126,27 +370,52 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m Model) View() string {
 	switch m.state {
 	case stateLoading:
-		return fmt.Sprintf("\n %s 正在思考...\n\n", m.spinner.View())
+		tipsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+		elapsed := time.Since(m.started).Truncate(100 * time.Millisecond)
+		return fmt.Sprintf("\n %s 正在思考... %s\n\n %s\n", m.spinner.View(), elapsed, tipsStyle.Render("Cancel: [Esc]"))
 
 	case stateReview:
-		boxStyle := lipgloss.NewStyle().
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("63")).
-			Padding(1, 2).
-			Width(60)
-
 		tipsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).MarginTop(1)
 
-		content := m.Msg
-		if content == "" {
-			content = "(空)"
+		tips := "Confirm: [Enter] | Edit: [e] | Retry: [r] | Cancel: [Ctrl+C or Esc]"
+		if len(m.candidates) > 0 {
+			tips = "Confirm: [Enter] | Edit: [e] | Retry: [r] | Co-authors: [a] | Cancel: [Ctrl+C or Esc]"
 		}
 
-		return fmt.Sprintf(
-			"\n%s\n%s\n",
-			boxStyle.Render(content),
-			tipsStyle.Render("Confirm: [Enter] | Edit: [e] | Retry: [r] | Cancel: [Ctrl+C or Esc]"),
-		)
+		message := m.reviewMessage()
+		if !m.showDiff() {
+			return fmt.Sprintf("\n%s\n%s\n", message, tipsStyle.Render(tips))
+		}
+
+		tips += "\nScroll: [↑/↓/PgUp/PgDn] | Next / previous file: [Tab/Shift+Tab]"
+		d := m.diff
+		d.setSize(m.diffSize(message))
+		body := message + "\n\n" + d.view()
+		if m.width >= sideBySideWidth {
+			body = lipgloss.JoinHorizontal(lipgloss.Top, message, "  ", d.view())
+		}
+		return fmt.Sprintf("\n%s\n%s\n", body, tipsStyle.Render(tips))
+
+	case stateCoAuthors:
+		cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+		tipsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).MarginTop(1)
+
+		var b strings.Builder
+		b.WriteString("\n 选择共同作者 (Co-authored-by):\n\n")
+		for i, author := range m.candidates {
+			pointer := "  "
+			if i == m.cursor {
+				pointer = cursorStyle.Render("▸ ")
+			}
+			check := "[ ]"
+			if m.selected[i] {
+				check = "[✔]"
+			}
+			fmt.Fprintf(&b, " %s%s %s\n", pointer, check, author)
+		}
+		b.WriteString(tipsStyle.Render("Move: [↑/↓] | Toggle: [Space] | Done: [Enter]"))
+		b.WriteString("\n")
+		return b.String()
 
 	case stateEditing:
 		// 5. 渲染单行输入框样式
@@ -155,21 +424,156 @@ func (m Model) View() string {
 			m.textInput.View(),
 		)
 
+	case stateCommitting:
+		return fmt.Sprintf("\n %s 正在提交...\n\n", m.spinner.View())
+
+	case stateCommitFailed:
+		boxStyle := lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("196")).
+			Padding(0, 1).
+			Width(80)
+		errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+		tipsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).MarginTop(1)
+
+		output := strings.TrimSpace(m.Output)
+		if output == "" {
+			output = m.err.Error()
+		}
+		return fmt.Sprintf(
+			"\n%s\n%s\n\n 提交信息:\n %s\n%s\n",
+			errStyle.Render("❌ 提交失败 (可能被 pre-commit / commit-msg 钩子拒绝):"),
+			boxStyle.Render(output),
+			strings.ReplaceAll(m.Msg, "\n", "\n "),
+			tipsStyle.Render("Retry: [Enter] | Edit: [e] | Fix with AI: [f] | Cancel: [Esc]"),
+		)
+
 	case stateError:
-		return fmt.Sprintf("\n❌ Error: %v\n", m.err)
+		errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+		noticeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+		tipsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).MarginTop(1)
+
+		var notice string
+		if m.notice != "" {
+			notice = noticeStyle.Render(m.notice) + "\n"
+		}
+		return fmt.Sprintf(
+			"\n%s\n%s%s\n",
+			errStyle.Render(fmt.Sprintf("❌ 生成失败: %v", m.err)),
+			notice,
+			tipsStyle.Render("Retry: [r] | Switch model: [m] | Write manually: [e] | Copy prompt: [c] | Quit: [Esc]"),
+		)
+
+	case stateSwitching:
+		cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+		tipsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).MarginTop(1)
+
+		var b strings.Builder
+		b.WriteString("\n 切换到其他模型重新生成:\n\n")
+		for i, alt := range m.alternatives {
+			pointer := "  "
+			if i == m.altCursor {
+				pointer = cursorStyle.Render("▸ ")
+			}
+			fmt.Fprintf(&b, " %s%s\n", pointer, alt.Name)
+		}
+		b.WriteString(tipsStyle.Render("Move: [↑/↓] | Select: [Enter] | Back: [Esc]"))
+		b.WriteString("\n")
+		return b.String()
 	}
 
 	return ""
 }
 
 // 辅助类型保持不变
-type generatedMsg string
 type errMsg error
 
-func (m Model) generateMsgCmd() tea.Msg {
-	res, err := m.client.GenerateCommitMessage(m.ctx, m.diff)
+// copyPrompt 把渲染后的提示词复制到剪贴板，返回操作结果
+func (m Model) copyPrompt() string {
+	messages, err := llm.ConstructMessages(m.opts)
 	if err != nil {
-		return errMsg(err)
+		return fmt.Sprintf("提示词渲染失败: %v", err)
+	}
+	var b strings.Builder
+	for _, msg := range messages {
+		fmt.Fprintf(&b, "===== %s =====\n%s\n\n", msg.Role, msg.Content)
+	}
+	if err := clipboard.WriteAll(b.String()); err != nil {
+		return fmt.Sprintf("复制到剪贴板失败: %v", err)
+	}
+	return "✅ 提示词已复制到剪贴板"
+}
+
+type startMsg struct{}
+
+type generatedMsg struct {
+	generation int
+	msg        string
+}
+
+type generateErrMsg struct {
+	generation int
+	err        error
+}
+
+type commitResultMsg struct {
+	output string
+	err    error
+}
+
+func (m Model) commitCmd() tea.Msg {
+	output, err := m.commit(m.Msg, m.CoAuthors())
+	return commitResultMsg{output: output, err: err}
+}
+
+func (m Model) generateMsgCmd(ctx context.Context, generation int, opts llm.PromptOptions) tea.Cmd {
+	return func() tea.Msg {
+		res, err := m.client.GenerateCommitMessage(ctx, opts)
+		if err != nil {
+			return generateErrMsg{generation: generation, err: err}
+		}
+		return generatedMsg{generation: generation, msg: res}
+	}
+}
+
+// reviewMessage 渲染预览界面中的提交信息框、共同作者和校验警告
+func (m Model) reviewMessage() string {
+	boxStyle := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("63")).
+		Padding(1, 2).
+		Width(messageBoxWidth)
+
+	content := m.Msg
+	if content == "" {
+		content = "(空)"
+	}
+
+	lines := []string{boxStyle.Render(content)}
+
+	coAuthorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	for _, author := range m.CoAuthors() {
+		lines = append(lines, coAuthorStyle.Render("Co-authored-by: "+author))
+	}
+
+	warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	for _, w := range m.warnings {
+		lines = append(lines, warningStyle.Render("⚠️ "+w))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// showDiff 表示预览界面是否显示 diff，在收到终端大小之前不显示
+func (m Model) showDiff() bool {
+	return !m.diff.empty() && m.width > 0 && m.height > 0
+}
+
+// diffSize 根据终端大小和提交信息占用的空间计算 diff 区域的大小。
+// 终端足够宽时 diff 在提交信息右侧，否则在下方
+func (m Model) diffSize(message string) (width, height int) {
+	const chrome = 5 // 顶部的空行和底部两行操作提示
+	if m.width >= sideBySideWidth {
+		return m.width - lipgloss.Width(message) - 2, m.height - chrome
 	}
-	return generatedMsg(res)
+	return m.width, max(m.height-lipgloss.Height(message)-chrome-1, m.diff.listHeight()+3)
 }
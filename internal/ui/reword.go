@@ -0,0 +1,254 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"aicommits/internal/llm"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// RewordItem 是一条等待重写信息的历史提交
+type RewordItem struct {
+	Hash     string
+	Old      string            // 原有的提交信息
+	New      string            // 生成或编辑后的提交信息
+	Accepted bool              // 是否采用新的提交信息
+	Options  llm.PromptOptions // 生成新信息时使用的参数
+
+	pending bool
+	err     error
+}
+
+// RewordModel 依次为每个提交生成新信息，并以前后对照的表格展示，供用户逐条采用或编辑
+type RewordModel struct {
+	client llm.Client
+	ctx    context.Context
+
+	Items      []RewordItem
+	cursor     int
+	generating int // 正在生成的条目，-1 表示空闲
+	state      sessionState
+	notice     string
+
+	spinner   spinner.Model
+	textInput textinput.Model
+
+	Confirmed bool
+}
+
+func NewRewordModel(ctx context.Context, client llm.Client, items []RewordItem) RewordModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+
+	ti := textinput.New()
+	ti.Placeholder = "在此编辑提交信息..."
+	ti.Focus()
+	ti.Width = 100
+
+	for i := range items {
+		items[i].pending = true
+	}
+
+	// 第一条在 Init 中开始生成
+	generating := -1
+	if len(items) > 0 {
+		generating = 0
+	}
+
+	return RewordModel{
+		client:     client,
+		ctx:        ctx,
+		Items:      items,
+		generating: generating,
+		state:      stateReview,
+		spinner:    s,
+		textInput:  ti,
+	}
+}
+
+func (m RewordModel) Init() tea.Cmd {
+	if m.generating < 0 {
+		return m.spinner.Tick
+	}
+	return tea.Batch(m.spinner.Tick, m.generateCmd(m.generating))
+}
+
+// next 开始生成下一条等待中的条目，一次只发出一个请求
+func (m RewordModel) next() (RewordModel, tea.Cmd) {
+	if m.generating >= 0 {
+		return m, nil
+	}
+	for i, item := range m.Items {
+		if item.pending {
+			m.generating = i
+			return m, m.generateCmd(i)
+		}
+	}
+	return m, nil
+}
+
+func (m RewordModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+
+	case tea.KeyMsg:
+		if m.state == stateEditing {
+			switch msg.String() {
+			case "enter", "esc":
+				item := &m.Items[m.cursor]
+				item.New = m.textInput.Value()
+				item.Accepted = strings.TrimSpace(item.New) != ""
+				m.state = stateReview
+				return m, nil
+			}
+			m.textInput, cmd = m.textInput.Update(msg)
+			return m, cmd
+		}
+
+		m.notice = ""
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.Items)-1 {
+				m.cursor++
+			}
+		case " ":
+			item := &m.Items[m.cursor]
+			if item.New != "" {
+				item.Accepted = !item.Accepted
+			}
+		case "e":
+			item := m.Items[m.cursor]
+			if item.pending {
+				return m, nil
+			}
+			m.state = stateEditing
+			m.textInput.SetValue(item.New)
+			m.textInput.CursorEnd()
+			return m, textinput.Blink
+		case "r":
+			item := &m.Items[m.cursor]
+			if !item.pending {
+				item.pending, item.New, item.Accepted, item.err = true, "", false, nil
+			}
+			return m.next()
+		case "enter":
+			if m.generating >= 0 {
+				m.notice = "仍有提交信息正在生成，请稍候"
+				return m, nil
+			}
+			m.Confirmed = true
+			return m, tea.Quit
+		}
+		return m, nil
+
+	case rewordResultMsg:
+		item := &m.Items[msg.index]
+		item.pending = false
+		item.New, item.err = msg.msg, msg.err
+		item.Accepted = msg.err == nil && strings.TrimSpace(msg.msg) != ""
+		m.generating = -1
+		return m.next()
+
+	case spinner.TickMsg:
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+func (m RewordModel) View() string {
+	if m.state == stateEditing {
+		return fmt.Sprintf(
+			"\n 编辑提交信息 (Enter 保存):\n\n %s\n\n",
+			m.textInput.View(),
+		)
+	}
+
+	hashStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	oldStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Strikethrough(true)
+	newStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	tipsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).MarginTop(1)
+	warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n 重写 %d 个提交的信息:\n\n", len(m.Items))
+	for i, item := range m.Items {
+		pointer := "  "
+		if i == m.cursor {
+			pointer = cursorStyle.Render("▸ ")
+		}
+		check := "[ ]"
+		if item.Accepted {
+			check = "[✔]"
+		}
+
+		old := oldStyle.Render(subjectOf(item.Old))
+		if !item.Accepted {
+			old = subjectOf(item.Old)
+		}
+		fmt.Fprintf(&b, " %s%s %s  %s\n", pointer, check, hashStyle.Render(shortHash(item.Hash)), old)
+
+		var after string
+		switch {
+		case item.pending && i == m.generating:
+			after = m.spinner.View() + " 正在生成..."
+		case item.pending:
+			after = "等待生成..."
+		case item.err != nil:
+			after = errStyle.Render(fmt.Sprintf("生成失败: %v", item.err))
+		default:
+			after = newStyle.Render(subjectOf(item.New))
+		}
+		fmt.Fprintf(&b, "              → %s\n", after)
+	}
+
+	if m.notice != "" {
+		b.WriteString("\n" + warningStyle.Render("⚠️ "+m.notice) + "\n")
+	}
+	b.WriteString(tipsStyle.Render("Move: [↑/↓] | Toggle: [Space] | Edit: [e] | Retry: [r] | Apply: [Enter] | Cancel: [Esc]"))
+	b.WriteString("\n")
+	return b.String()
+}
+
+type rewordResultMsg struct {
+	index int
+	msg   string
+	err   error
+}
+
+func (m RewordModel) generateCmd(index int) tea.Cmd {
+	opts := m.Items[index].Options
+	return func() tea.Msg {
+		res, err := m.client.GenerateCommitMessage(m.ctx, opts)
+		return rewordResultMsg{index: index, msg: res, err: err}
+	}
+}
+
+func subjectOf(msg string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+	return subject
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
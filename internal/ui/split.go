@@ -0,0 +1,280 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"aicommits/internal/llm"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SplitModel 展示模型建议的拆分方案，允许调整变更单元的归属和每个提交的信息
+type SplitModel struct {
+	client llm.Client
+	opts   llm.PromptOptions
+	ctx    context.Context
+	units  map[string]llm.SplitUnit
+
+	order  []string // 变更单元的原始顺序，用于请求模型
+	state  sessionState
+	Groups []llm.SplitGroup
+	cursor int    // 光标所在的变更单元在展开列表中的位置
+	notice string // 确认失败等提示
+	err    error
+
+	spinner   spinner.Model
+	textInput textinput.Model
+
+	Confirmed bool
+}
+
+func NewSplitModel(ctx context.Context, client llm.Client, opts llm.PromptOptions, units []llm.SplitUnit) SplitModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+
+	ti := textinput.New()
+	ti.Placeholder = "在此编辑提交信息..."
+	ti.Focus()
+	ti.Width = 100
+
+	m := SplitModel{
+		client:    client,
+		opts:      opts,
+		ctx:       ctx,
+		units:     make(map[string]llm.SplitUnit, len(units)),
+		state:     stateLoading,
+		spinner:   s,
+		textInput: ti,
+	}
+	for _, u := range units {
+		m.units[u.ID] = u
+		m.order = append(m.order, u.ID)
+	}
+	return m
+}
+
+// Err 返回生成拆分方案失败的错误，没有失败时返回 nil
+func (m SplitModel) Err() error {
+	return m.err
+}
+
+func (m SplitModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.proposeCmd)
+}
+
+func (m SplitModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+
+	case tea.KeyMsg:
+		switch m.state {
+
+		case stateReview:
+			m.notice = ""
+			switch msg.String() {
+			case "q", "ctrl+c", "esc":
+				return m, tea.Quit
+			case "up", "k":
+				if m.cursor > 0 {
+					m.cursor--
+				}
+			case "down", "j":
+				if m.cursor < m.unitCount()-1 {
+					m.cursor++
+				}
+			case "left", "h":
+				m.moveUnit(-1)
+			case "right", "l":
+				m.moveUnit(1)
+			case "n":
+				m.moveUnit(len(m.Groups))
+			case "e":
+				group, _ := m.position()
+				m.state = stateEditing
+				m.textInput.SetValue(m.Groups[group].Message)
+				m.textInput.CursorEnd()
+				return m, textinput.Blink
+			case "r":
+				m.state = stateLoading
+				m.Groups = nil
+				return m, tea.Batch(m.spinner.Tick, m.proposeCmd)
+			case "enter":
+				for i, g := range m.Groups {
+					if strings.TrimSpace(g.Message) == "" {
+						m.notice = fmt.Sprintf("提交 %d 的信息为空，请先按 [e] 编辑", i+1)
+						return m, nil
+					}
+				}
+				m.Confirmed = true
+				return m, tea.Quit
+			}
+			return m, nil
+
+		case stateEditing:
+			switch msg.String() {
+			case "enter", "esc":
+				group, _ := m.position()
+				m.Groups[group].Message = m.textInput.Value()
+				m.state = stateReview
+				return m, nil
+			}
+			m.textInput, cmd = m.textInput.Update(msg)
+			return m, cmd
+
+		default:
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+		}
+
+	case splitPlanMsg:
+		m.state = stateReview
+		m.Groups = msg
+		m.cursor = 0
+		return m, nil
+
+	case errMsg:
+		m.state = stateError
+		m.err = error(msg)
+		return m, tea.Quit
+
+	case spinner.TickMsg:
+		if m.state == stateLoading {
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+	}
+
+	return m, nil
+}
+
+// position 返回光标所在的提交和该变更单元在提交中的位置
+func (m SplitModel) position() (int, int) {
+	offset := m.cursor
+	for i, g := range m.Groups {
+		if offset < len(g.Units) {
+			return i, offset
+		}
+		offset -= len(g.Units)
+	}
+	return len(m.Groups) - 1, 0
+}
+
+func (m SplitModel) unitCount() int {
+	count := 0
+	for _, g := range m.Groups {
+		count += len(g.Units)
+	}
+	return count
+}
+
+// moveUnit 将光标所在的变更单元移动到相邻的提交，delta 超出范围时新建一个提交
+// 被移空的提交会被删除，光标跟随被移动的单元
+func (m *SplitModel) moveUnit(delta int) {
+	if len(m.Groups) == 0 {
+		return
+	}
+	from, index := m.position()
+	to := from + delta
+	if to < 0 {
+		return
+	}
+	if to >= len(m.Groups) {
+		if len(m.Groups[from].Units) == 1 {
+			// 已经独占一个提交，没有必要再新建
+			return
+		}
+		m.Groups = append(m.Groups, llm.SplitGroup{})
+		to = len(m.Groups) - 1
+	}
+
+	id := m.Groups[from].Units[index]
+	m.Groups[from].Units = append(m.Groups[from].Units[:index:index], m.Groups[from].Units[index+1:]...)
+	m.Groups[to].Units = append(m.Groups[to].Units, id)
+
+	if len(m.Groups[from].Units) == 0 {
+		m.Groups = append(m.Groups[:from], m.Groups[from+1:]...)
+		if to > from {
+			to--
+		}
+	}
+
+	m.cursor = 0
+	for i := 0; i < to; i++ {
+		m.cursor += len(m.Groups[i].Units)
+	}
+	m.cursor += len(m.Groups[to].Units) - 1
+}
+
+func (m SplitModel) View() string {
+	switch m.state {
+	case stateLoading:
+		return fmt.Sprintf("\n %s 正在分析如何拆分提交...\n\n", m.spinner.View())
+
+	case stateReview:
+		titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("63")).Bold(true)
+		cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+		unitStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+		tipsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).MarginTop(1)
+		warningStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "\n 拆分为 %d 个提交:\n", len(m.Groups))
+		row := 0
+		for i, g := range m.Groups {
+			subject, _, _ := strings.Cut(g.Message, "\n")
+			if subject == "" {
+				subject = "(空)"
+			}
+			b.WriteString("\n " + titleStyle.Render(fmt.Sprintf("[%d] %s", i+1, subject)) + "\n")
+			for _, id := range g.Units {
+				line := "   " + unitStyle.Render(m.units[id].Label)
+				if row == m.cursor {
+					line = " " + cursorStyle.Render("▸ "+m.units[id].Label)
+				}
+				b.WriteString(line + "\n")
+				row++
+			}
+		}
+		if m.notice != "" {
+			b.WriteString("\n" + warningStyle.Render("⚠️ "+m.notice) + "\n")
+		}
+		b.WriteString(tipsStyle.Render("Move: [↑/↓] | Reassign: [←/→] | New commit: [n] | Edit: [e] | Retry: [r] | Confirm: [Enter] | Cancel: [Esc]"))
+		b.WriteString("\n")
+		return b.String()
+
+	case stateEditing:
+		return fmt.Sprintf(
+			"\n 编辑提交信息 (Enter 保存):\n\n %s\n\n",
+			m.textInput.View(),
+		)
+
+	case stateError:
+		// 错误由调用方输出
+		return ""
+	}
+
+	return ""
+}
+
+type splitPlanMsg []llm.SplitGroup
+
+func (m SplitModel) proposeCmd() tea.Msg {
+	units := make([]llm.SplitUnit, 0, len(m.order))
+	for _, id := range m.order {
+		units = append(units, m.units[id])
+	}
+
+	groups, err := llm.ProposeSplit(m.ctx, m.client, m.opts, units)
+	if err != nil {
+		return errMsg(err)
+	}
+	return splitPlanMsg(groups)
+}
@@ -0,0 +1,370 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"aicommits/internal/diff"
+	"aicommits/internal/git"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type stageState int
+
+const (
+	stageFiles stageState = iota
+	stageHunks
+)
+
+// hunkPreviewLines 是 hunk 列表中每个 hunk 最多显示的行数
+const hunkPreviewLines = 8
+
+// stageChromeLines 是列表之外的标题、提示、说明和上下滚动标记占用的行数
+const stageChromeLines = 10
+
+// StageModel 是生成提交信息之前选择暂存内容的界面：按文件暂存，或只暂存文件中的部分 hunk
+type StageModel struct {
+	state  stageState
+	files  []git.FileStatus
+	cursor int
+
+	hunkFile   diff.File // 正在选择 hunk 的文件，内容是工作区相对于暂存区的 diff
+	hunkPicked []bool
+	hunkCursor int
+
+	notice string
+	err    error
+	height int // 终端高度，列表超出时只显示光标附近的部分
+
+	Confirmed bool
+}
+
+func NewStageModel() StageModel {
+	m := StageModel{}
+	m.refresh()
+	return m
+}
+
+// Err 返回读取状态失败的错误
+func (m StageModel) Err() error {
+	return m.err
+}
+
+// refresh 重新读取 git status，每次暂存或取消暂存之后调用
+func (m *StageModel) refresh() {
+	files, err := git.Status()
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.files = files
+	if m.cursor >= len(m.files) {
+		m.cursor = max(len(m.files)-1, 0)
+	}
+}
+
+func (m StageModel) Init() tea.Cmd {
+	if m.err != nil {
+		return tea.Quit
+	}
+	return nil
+}
+
+func (m StageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		m.height = size.Height
+		return m, nil
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if key.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+
+	m.notice = ""
+	if m.state == stageHunks {
+		return m.updateHunks(key)
+	}
+
+	switch key.String() {
+	case "q", "esc":
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.files)-1 {
+			m.cursor++
+		}
+	case " ":
+		if len(m.files) > 0 {
+			m.toggle(m.files[m.cursor])
+		}
+	case "a":
+		// 只暂存列表中显示的文件，路径相对于仓库根目录，在子目录中同样有效
+		paths := make([]string, 0, len(m.files))
+		for _, f := range m.files {
+			paths = append(paths, f.Path)
+		}
+		if len(paths) > 0 {
+			if err := git.StagePaths(paths...); err != nil {
+				m.notice = err.Error()
+			}
+		}
+		m.refresh()
+	case "u":
+		// 合并、cherry-pick 和 revert 过程中暂存区里是已经解决的结果，全部取消会丢失这些解决
+		if op := (git.Repo{}).State().Operation; op != git.OpNone {
+			m.notice = fmt.Sprintf("正在进行 %s，不能取消全部暂存，请逐个文件操作", op)
+			return m, nil
+		}
+		var paths []string
+		for _, f := range m.files {
+			if f.HasStaged() {
+				paths = append(paths, f.Path)
+				if f.OrigPath != "" {
+					paths = append(paths, f.OrigPath)
+				}
+			}
+		}
+		if len(paths) > 0 {
+			if err := git.UnstagePaths(paths...); err != nil {
+				m.notice = err.Error()
+			}
+		}
+		m.refresh()
+	case "right", "l":
+		if len(m.files) > 0 {
+			m.openHunks(m.files[m.cursor])
+		}
+	case "enter":
+		if !git.HasStagedChanges() {
+			m.notice = "暂存区为空，请至少选择一个文件"
+			return m, nil
+		}
+		m.Confirmed = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// toggle 全部暂存文件，文件已完全暂存时取消暂存
+func (m *StageModel) toggle(f git.FileStatus) {
+	var err error
+	if f.HasStaged() && !f.HasUnstaged() {
+		paths := []string{f.Path}
+		if f.OrigPath != "" {
+			paths = append(paths, f.OrigPath)
+		}
+		err = git.UnstagePaths(paths...)
+	} else {
+		err = git.StagePaths(f.Path)
+	}
+	if err != nil {
+		m.notice = err.Error()
+	}
+	m.refresh()
+}
+
+// openHunks 进入 hunk 选择，只有已跟踪且有未暂存修改的文件可以按 hunk 暂存
+func (m *StageModel) openHunks(f git.FileStatus) {
+	if f.IsUntracked() {
+		m.notice = "未跟踪的文件只能整体暂存"
+		return
+	}
+	raw, err := git.WorktreeDiff(f.Path)
+	if err != nil {
+		m.notice = err.Error()
+		return
+	}
+	files := diff.Parse(raw)
+	if len(files) == 0 || len(files[0].Hunks) == 0 {
+		m.notice = "该文件没有可以按 hunk 暂存的修改"
+		return
+	}
+	m.hunkFile = files[0]
+	m.hunkPicked = make([]bool, len(m.hunkFile.Hunks))
+	m.hunkCursor = 0
+	m.state = stageHunks
+}
+
+func (m StageModel) updateHunks(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc", "left", "h":
+		m.state = stageFiles
+	case "up", "k":
+		if m.hunkCursor > 0 {
+			m.hunkCursor--
+		}
+	case "down", "j":
+		if m.hunkCursor < len(m.hunkFile.Hunks)-1 {
+			m.hunkCursor++
+		}
+	case " ":
+		m.hunkPicked[m.hunkCursor] = !m.hunkPicked[m.hunkCursor]
+	case "enter":
+		// 只保留选中的 hunk，组成补丁应用到暂存区
+		partial := m.hunkFile
+		partial.Hunks = nil
+		for i, h := range m.hunkFile.Hunks {
+			if m.hunkPicked[i] {
+				partial.Hunks = append(partial.Hunks, h)
+			}
+		}
+		if len(partial.Hunks) > 0 {
+			if err := git.ApplyCached(partial.String()); err != nil {
+				m.notice = err.Error()
+				return m, nil
+			}
+		}
+		m.state = stageFiles
+		m.refresh()
+	}
+	return m, nil
+}
+
+func (m StageModel) View() string {
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	tipsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).MarginTop(1)
+	noticeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	addStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	delStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	var b strings.Builder
+	if m.state == stageHunks {
+		fmt.Fprintf(&b, "\n 选择要暂存的 hunk (%s):\n\n", m.hunkFile.Path())
+		heights := make([]int, len(m.hunkFile.Hunks))
+		for i, h := range m.hunkFile.Hunks {
+			heights[i] = 1 + min(len(h.Lines), hunkPreviewLines+1)
+		}
+		start, end := m.window(heights, m.hunkCursor)
+		b.WriteString(moreAbove(start, "个 hunk"))
+		for i := start; i < end; i++ {
+			h := m.hunkFile.Hunks[i]
+			pointer := "  "
+			if i == m.hunkCursor {
+				pointer = cursorStyle.Render("▸ ")
+			}
+			check := "[ ]"
+			if m.hunkPicked[i] {
+				check = "[✔]"
+			}
+			fmt.Fprintf(&b, " %s%s %s\n", pointer, check, dimStyle.Render(h.Header))
+			for j, line := range h.Lines {
+				if j == hunkPreviewLines {
+					fmt.Fprintf(&b, "       %s\n", dimStyle.Render(fmt.Sprintf("... 还有 %d 行", len(h.Lines)-j)))
+					break
+				}
+				switch {
+				case strings.HasPrefix(line, "+"):
+					line = addStyle.Render(line)
+				case strings.HasPrefix(line, "-"):
+					line = delStyle.Render(line)
+				}
+				fmt.Fprintf(&b, "       %s\n", line)
+			}
+		}
+		b.WriteString(moreBelow(len(heights)-end, "个 hunk"))
+		if m.notice != "" {
+			b.WriteString("\n " + noticeStyle.Render(m.notice) + "\n")
+		}
+		b.WriteString(tipsStyle.Render("Move: [↑/↓] | Toggle: [Space] | Stage selected: [Enter] | Back: [Esc]"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	b.WriteString("\n 选择要暂存的文件 (已暂存 │ 未暂存):\n\n")
+	if len(m.files) == 0 {
+		b.WriteString(dimStyle.Render("   工作区没有任何变更") + "\n")
+	}
+	stat := func(added, deleted int) string {
+		return addStyle.Render(fmt.Sprintf("+%d", added)) + " " + delStyle.Render(fmt.Sprintf("-%d", deleted))
+	}
+	width := 0
+	for _, f := range m.files {
+		width = max(width, len(f.Path))
+	}
+	heights := make([]int, len(m.files))
+	for i := range heights {
+		heights[i] = 1
+	}
+	start, end := m.window(heights, m.cursor)
+	b.WriteString(moreAbove(start, "个文件"))
+	for i := start; i < end; i++ {
+		f := m.files[i]
+		pointer := "  "
+		if i == m.cursor {
+			pointer = cursorStyle.Render("▸ ")
+		}
+		check := "[ ]"
+		switch {
+		case f.HasStaged() && !f.HasUnstaged():
+			check = "[✔]"
+		case f.HasStaged():
+			check = "[~]"
+		}
+
+		unstaged := stat(f.UnstagedAdded, f.UnstagedDeleted)
+		if f.IsUntracked() {
+			unstaged = addStyle.Render("新文件")
+		}
+		fmt.Fprintf(&b, " %s%s %c%c %-*s  %s │ %s\n", pointer, check, f.Staged, f.Unstaged, width, f.Path,
+			stat(f.StagedAdded, f.StagedDeleted), unstaged)
+	}
+	b.WriteString(moreBelow(len(heights)-end, "个文件"))
+	if m.notice != "" {
+		b.WriteString("\n " + noticeStyle.Render(m.notice) + "\n")
+	}
+	b.WriteString(tipsStyle.Render("Move: [↑/↓] | Toggle: [Space] | Hunks: [→] | Stage all: [a] | Unstage all: [u] | Continue: [Enter] | Cancel: [Esc]"))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// window 返回在终端高度内能显示的行 [start, end)，heights 是每一行占用的终端行数，
+// 从光标所在的行开始向上下两侧扩展，光标所在的行总是可见
+func (m StageModel) window(heights []int, cursor int) (int, int) {
+	if len(heights) == 0 {
+		return 0, 0
+	}
+	if m.height == 0 {
+		return 0, len(heights)
+	}
+	budget := max(m.height-stageChromeLines, 1)
+	start, end := cursor, cursor+1
+	used := heights[cursor]
+	for grew := true; grew; {
+		grew = false
+		if end < len(heights) && used+heights[end] <= budget {
+			used += heights[end]
+			end++
+			grew = true
+		}
+		if start > 0 && used+heights[start-1] <= budget {
+			start--
+			used += heights[start]
+			grew = true
+		}
+	}
+	return start, end
+}
+
+// moreAbove 和 moreBelow 标记列表上下被省略的条目数量
+func moreAbove(n int, unit string) string {
+	if n == 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(fmt.Sprintf("   ↑ 上方还有 %d %s", n, unit)) + "\n"
+}
+
+func moreBelow(n int, unit string) string {
+	if n == 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(fmt.Sprintf("   ↓ 下方还有 %d %s", n, unit)) + "\n"
+}
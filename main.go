@@ -2,13 +2,11 @@ package main
 
 import (
 	"aicommits/cmd"
-	"fmt"
 	"os"
 )
 
 func main() {
 	if err := cmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }